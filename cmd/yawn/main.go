@@ -19,6 +19,7 @@ var (
 
 	flagAPIKey         string
 	flagAutoStage      bool
+	flagPick           bool
 	flagAutoPush       bool
 	flagGenerateConfig bool
 )
@@ -68,6 +69,10 @@ var rootCmd = &cobra.Command{
 		if cmd.Flags().Changed("auto-stage") {
 			flags.AutoStage = &flagAutoStage
 		}
+		if cmd.Flags().Changed("pick") {
+			stageMode := config.StageModePick
+			flags.StageMode = &stageMode
+		}
 		if cmd.Flags().Changed("auto-push") {
 			flags.AutoPush = &flagAutoPush
 		}
@@ -77,6 +82,10 @@ var rootCmd = &cobra.Command{
 			ui.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
 			return err
 		}
+		if err := configureGitClient(gitClient, cfg); err != nil {
+			ui.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
+			return err
+		}
 
 		yawnApp := app.NewApp(cfg, gitClient)
 		if err := yawnApp.Run(cmd.Context()); err != nil {
@@ -113,6 +122,10 @@ var forcePushCmd = &cobra.Command{
 			ui.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
 			return err
 		}
+		if err := configureGitClient(gitClient, cfg); err != nil {
+			ui.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
+			return err
+		}
 
 		yawnApp := app.NewApp(cfg, gitClient)
 		if err := yawnApp.RunForcePush(); err != nil {
@@ -144,6 +157,10 @@ var squashCmd = &cobra.Command{
 			ui.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
 			return err
 		}
+		if err := configureGitClient(gitClient, cfg); err != nil {
+			ui.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
+			return err
+		}
 
 		yawnApp := app.NewApp(cfg, gitClient)
 		if err := yawnApp.RunSquash(cmd.Context()); err != nil {
@@ -154,6 +171,176 @@ var squashCmd = &cobra.Command{
 	},
 }
 
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Split staged changes into several AI-planned commits",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectPath, err := os.Getwd()
+		if err != nil {
+			ui.PrintError(fmt.Sprintf("Error getting current directory: %v", err))
+			return err
+		}
+
+		gitClient, err := git.NewExecGitClient()
+		if err != nil {
+			ui.PrintError(err.Error())
+			return err
+		}
+
+		cfg, err := config.LoadConfig(projectPath, config.CLIFlags{})
+		if err != nil {
+			ui.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
+			return err
+		}
+		if err := configureGitClient(gitClient, cfg); err != nil {
+			ui.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
+			return err
+		}
+
+		yawnApp := app.NewApp(cfg, gitClient)
+		if err := yawnApp.RunSplit(cmd.Context()); err != nil {
+			ui.PrintError(err.Error())
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+var amendCmd = &cobra.Command{
+	Use:   "amend",
+	Short: "Regenerate the message of the last commit, optionally adding current changes",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectPath, err := os.Getwd()
+		if err != nil {
+			ui.PrintError(fmt.Sprintf("Error getting current directory: %v", err))
+			return err
+		}
+
+		gitClient, err := git.NewExecGitClient()
+		if err != nil {
+			ui.PrintError(err.Error())
+			return err
+		}
+
+		flags := config.CLIFlags{}
+		if cmd.Flags().Changed("auto-stage") {
+			flags.AutoStage = &flagAutoStage
+		}
+		if cmd.Flags().Changed("pick") {
+			stageMode := config.StageModePick
+			flags.StageMode = &stageMode
+		}
+
+		cfg, err := config.LoadConfig(projectPath, flags)
+		if err != nil {
+			ui.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
+			return err
+		}
+		if err := configureGitClient(gitClient, cfg); err != nil {
+			ui.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
+			return err
+		}
+
+		yawnApp := app.NewApp(cfg, gitClient)
+		if err := yawnApp.RunAmend(cmd.Context()); err != nil {
+			ui.PrintError(err.Error())
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+var rewordCmd = &cobra.Command{
+	Use:   "reword <range>",
+	Short: "Regenerate the messages of the commits in a range such as origin/main..HEAD",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectPath, err := os.Getwd()
+		if err != nil {
+			ui.PrintError(fmt.Sprintf("Error getting current directory: %v", err))
+			return err
+		}
+
+		gitClient, err := git.NewExecGitClient()
+		if err != nil {
+			ui.PrintError(err.Error())
+			return err
+		}
+
+		cfg, err := config.LoadConfig(projectPath, config.CLIFlags{})
+		if err != nil {
+			ui.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
+			return err
+		}
+		if err := configureGitClient(gitClient, cfg); err != nil {
+			ui.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
+			return err
+		}
+
+		yawnApp := app.NewApp(cfg, gitClient)
+		if err := yawnApp.RunReword(cmd.Context(), args[0]); err != nil {
+			ui.PrintError(err.Error())
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert the most recent yawn commit, amend, squash, split, reword, force-push, or remote URL change",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectPath, err := os.Getwd()
+		if err != nil {
+			ui.PrintError(fmt.Sprintf("Error getting current directory: %v", err))
+			return err
+		}
+
+		gitClient, err := git.NewExecGitClient()
+		if err != nil {
+			ui.PrintError(err.Error())
+			return err
+		}
+
+		cfg, err := config.LoadConfig(projectPath, config.CLIFlags{})
+		if err != nil {
+			ui.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
+			return err
+		}
+		if err := configureGitClient(gitClient, cfg); err != nil {
+			ui.PrintError(fmt.Sprintf("Error loading configuration: %v", err))
+			return err
+		}
+
+		yawnApp := app.NewApp(cfg, gitClient)
+		if err := yawnApp.RunUndo(); err != nil {
+			ui.PrintError(err.Error())
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+func configureGitClient(gitClient *git.ExecGitClient, cfg config.Config) error {
+	gitClient.RankWeights = git.RankWeights(cfg.DiffRanking)
+	gitClient.Redaction = git.RedactionRules(cfg.Redaction)
+	if cfg.StageGuard.Enabled {
+		gitClient.StageGuard = &git.StagingGuard{
+			Deny:         cfg.StageGuard.Deny,
+			MaxFileBytes: int64(cfg.StageGuard.MaxFileMB) << 20,
+		}
+	}
+	if !cfg.SecretScan.Enabled {
+		return nil
+	}
+	scanner, err := git.NewSecretScanner(cfg.SecretScan.Patterns, cfg.SecretScan.EntropyThreshold)
+	if err != nil {
+		return err
+	}
+	gitClient.Secrets = scanner
+	return nil
+}
+
 func init() {
 	if builtBy == "goreleaser" {
 		ui.Version = version
@@ -181,11 +368,19 @@ func init() {
 
 	rootCmd.Flags().StringVar(&flagAPIKey, "api-key", "", "AI provider API key (overrides config/env)")
 	rootCmd.Flags().BoolVar(&flagAutoStage, "auto-stage", false, "Automatically stage all unstaged changes without prompting")
+	rootCmd.Flags().BoolVar(&flagPick, "pick", false, "Pick which changed files to stage instead of staging everything")
 	rootCmd.Flags().BoolVar(&flagAutoPush, "auto-push", false, "Automatically push after commit")
 	rootCmd.Flags().BoolVar(&flagGenerateConfig, "generate-config", false, "Print default configuration TOML to stdout and exit")
 
 	rootCmd.SetVersionTemplate(`{{printf "%s version %s\n" .Name .Version}}`)
 	rootCmd.AddCommand(squashCmd)
+	rootCmd.AddCommand(splitCmd)
+
+	amendCmd.Flags().BoolVar(&flagAutoStage, "auto-stage", false, "Add all current changes to the amended commit without prompting")
+	amendCmd.Flags().BoolVar(&flagPick, "pick", false, "Pick which changed files to add to the amended commit")
+	rootCmd.AddCommand(amendCmd)
+	rootCmd.AddCommand(rewordCmd)
+	rootCmd.AddCommand(undoCmd)
 
 	forcePushCmd.Flags().BoolVar(&flagAutoPush, "auto-push", false, "Force-push without confirmation prompt")
 	rootCmd.AddCommand(forcePushCmd)
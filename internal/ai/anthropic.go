@@ -0,0 +1,112 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	anthropicMessagesPath = "/v1/messages"
+	anthropicVersion      = "2023-06-01"
+	anthropicMaxTokens    = 1024
+	anthropicOverloaded   = 529
+)
+
+type anthropicClient struct {
+	endpoint string
+	apiKey   string
+	model    string
+}
+
+type anthropicMessagesRequest struct {
+	Model       string              `json:"model"`
+	System      string              `json:"system"`
+	Messages    []geminiChatMessage `json:"messages"`
+	MaxTokens   int                 `json:"max_tokens"`
+	Stream      bool                `json:"stream"`
+	Temperature float32             `json:"temperature"`
+}
+
+type anthropicEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func newAnthropicClient(baseURL, apiKey, model string) *anthropicClient {
+	return &anthropicClient{
+		endpoint: anthropicEndpoint(baseURL),
+		apiKey:   apiKey,
+		model:    model,
+	}
+}
+
+func (c *anthropicClient) GenerateCommitMessageStream(ctx context.Context, systemPrompt, userContent string) (Stream, error) {
+	return startJSONStream(ctx, c.endpoint, "", anthropicMessagesRequest{
+		Model:  c.model,
+		System: systemPrompt,
+		Messages: []geminiChatMessage{
+			{Role: "user", Content: userContent},
+		},
+		MaxTokens:   anthropicMaxTokens,
+		Stream:      true,
+		Temperature: 0,
+	}, map[string]string{
+		"x-api-key":         c.apiKey,
+		"anthropic-version": anthropicVersion,
+	}, parseAnthropicEvent)
+}
+
+func anthropicEndpoint(baseURL string) string {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return anthropicMessagesEndpoint
+	}
+	if strings.HasSuffix(baseURL, anthropicMessagesPath) {
+		return baseURL
+	}
+	return baseURL + anthropicMessagesPath
+}
+
+func parseAnthropicEvent(event string, data []byte) (string, bool, error) {
+	var payload anthropicEvent
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return "", false, err
+	}
+	if event == "" {
+		event = payload.Type
+	}
+	switch event {
+	case "content_block_delta":
+		if payload.Delta.Type == "text_delta" {
+			return payload.Delta.Text, false, nil
+		}
+	case "message_stop":
+		return "", true, nil
+	case "error":
+		return "", false, anthropicStreamError(payload)
+	}
+	return "", false, nil
+}
+
+func anthropicStreamError(payload anthropicEvent) error {
+	if payload.Error == nil {
+		return fmt.Errorf("provider stream error")
+	}
+	if payload.Error.Type == "overloaded_error" {
+		return statusError{StatusCode: anthropicOverloaded, Body: payload.Error.Message}
+	}
+	if payload.Error.Type == "rate_limit_error" {
+		return statusError{StatusCode: http.StatusTooManyRequests, Body: payload.Error.Message}
+	}
+	return fmt.Errorf("provider stream error: %s", payload.Error.Message)
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/Mayurifag/yawn/internal/config"
 )
@@ -20,50 +23,83 @@ type Client interface {
 
 const (
 	geminiChatCompletionsEndpoint = "https://generativelanguage.googleapis.com/v1beta/openai/chat/completions"
+	anthropicMessagesEndpoint     = "https://api.anthropic.com/v1/messages"
 )
 
 type statusError struct {
 	StatusCode int
 	Body       string
+	RetryAfter time.Duration
 }
 
 func (e statusError) Error() string {
-	body := strings.TrimSpace(e.Body)
-	if body == "" {
-		return fmt.Sprintf("provider returned HTTP %d", e.StatusCode)
+	message := fmt.Sprintf("provider returned HTTP %d", e.StatusCode)
+	if e.RetryAfter > 0 {
+		message += fmt.Sprintf(" (retry after %s)", e.RetryAfter)
 	}
-	return fmt.Sprintf("provider returned HTTP %d: %s", e.StatusCode, body)
+	if body := strings.TrimSpace(e.Body); body != "" {
+		message += ": " + body
+	}
+	return message
 }
 
 func NewClient(cfg config.Config) (Client, error) {
-	mainProvider := cfg.GetMainProvider()
-	mainClient, err := newProviderClient(mainProvider, cfg.GetProviderConfig(mainProvider))
+	chain := cfg.GetProviderChain()
+	mainProvider := chain[0]
+	mainCfg := cfg.GetProviderConfig(mainProvider)
+	mainClient, err := newProviderClient(mainProvider, mainCfg)
 	if err != nil {
 		return nil, err
 	}
 
-	fallbackProvider := cfg.GetFallbackProvider()
-	if fallbackProvider == "" {
-		return mainClient, nil
+	links := []fallbackLink{{
+		provider: mainProvider,
+		timeout:  mainCfg.GetTimeout(),
+		client:   func() (Client, error) { return mainClient, nil },
+	}}
+	for _, provider := range chain[1:] {
+		providerCfg := cfg.GetProviderConfig(provider)
+		links = append(links, fallbackLink{
+			provider: provider,
+			timeout:  providerCfg.GetTimeout(),
+			client: func() (Client, error) {
+				return newProviderClient(provider, providerCfg)
+			},
+		})
+	}
+	if hedgeAfter := cfg.GetHedgeAfter(); hedgeAfter > 0 && len(links) > 1 {
+		return &hedgedClient{links: links, hedgeAfter: hedgeAfter}, nil
 	}
-	fallbackCfg := cfg.GetProviderConfig(fallbackProvider)
-	return &fallbackClient{
-		primary: mainClient,
-		fallback: func() (Client, error) {
-			return newProviderClient(fallbackProvider, fallbackCfg)
-		},
-	}, nil
+	return &fallbackClient{links: links}, nil
 }
 
 func newProviderClient(provider string, providerCfg config.ProviderConfig) (Client, error) {
-	if config.ProviderRequiresAPIKey(provider) && providerCfg.APIKey == "" {
+	if config.ProviderRequiresAPIKey(providerCfg.Type) && providerCfg.APIKey == "" {
 		return nil, fmt.Errorf("API key is required for provider %q", provider)
 	}
-	switch provider {
+	switch providerCfg.Type {
 	case config.ProviderGemini:
 		return newGeminiClient(providerCfg.APIKey, providerCfg.Model), nil
 	case config.ProviderOpenCodeCLI:
 		return newOpenCodeCLIClient(providerCfg.Model), nil
+	case config.ProviderOpenAICompat:
+		if providerCfg.BaseURL == "" {
+			return nil, fmt.Errorf("base_url is required for provider %q", provider)
+		}
+		if providerCfg.Model == "" {
+			return nil, fmt.Errorf("model is required for provider %q", provider)
+		}
+		return newOpenAICompatibleClient(providerCfg.BaseURL, providerCfg.APIKey, providerCfg.Model, providerCfg.Headers), nil
+	case config.ProviderOllama:
+		return newOllamaClient(providerCfg.Host, providerCfg.Model, providerCfg.KeepAlive, providerCfg.NumCtx), nil
+	case config.ProviderAnthropic:
+		return newAnthropicClient(providerCfg.BaseURL, providerCfg.APIKey, providerCfg.Model), nil
+	case config.ProviderCommand:
+		client, err := newCommandClient(providerCfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid command provider %q: %w", provider, err)
+		}
+		return client, nil
 	default:
 		return nil, fmt.Errorf("unsupported provider %q", provider)
 	}
@@ -73,13 +109,14 @@ func IsTransientError(err error) bool {
 	if err == nil {
 		return false
 	}
-	if errors.Is(err, context.DeadlineExceeded) {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
 		return true
 	}
 	var statusErr statusError
 	if errors.As(err, &statusErr) {
 		return statusErr.StatusCode == 408 || statusErr.StatusCode == 429 || statusErr.StatusCode == 500 ||
-			statusErr.StatusCode == 502 || statusErr.StatusCode == 503 || statusErr.StatusCode == 504
+			statusErr.StatusCode == 502 || statusErr.StatusCode == 503 || statusErr.StatusCode == 504 ||
+			statusErr.StatusCode == anthropicOverloaded
 	}
 	var netErr net.Error
 	return errors.As(err, &netErr) && netErr.Timeout()
@@ -0,0 +1,204 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Mayurifag/yawn/internal/config"
+)
+
+const (
+	commandModelPlaceholder      = "{model}"
+	commandPromptFilePlaceholder = "{prompt_file}"
+)
+
+type commandClient struct {
+	argv        []string
+	model       string
+	promptInput string
+	output      string
+	outputField string
+}
+
+func newCommandClient(providerCfg config.ProviderConfig) (*commandClient, error) {
+	if len(providerCfg.Command) == 0 || strings.TrimSpace(providerCfg.Command[0]) == "" {
+		return nil, errors.New("command is required")
+	}
+	promptInput := providerCfg.GetPromptInput()
+	if promptInput != config.CommandPromptStdin && promptInput != config.CommandPromptFile {
+		return nil, fmt.Errorf("unsupported prompt_input %q", providerCfg.PromptInput)
+	}
+	output := providerCfg.GetOutput()
+	if output != config.CommandOutputText && output != config.CommandOutputJSONLines && output != config.CommandOutputSSE {
+		return nil, fmt.Errorf("unsupported output %q", providerCfg.Output)
+	}
+	if output == config.CommandOutputJSONLines && providerCfg.OutputField == "" {
+		return nil, errors.New("output_field is required for json_lines output")
+	}
+	return &commandClient{
+		argv:        providerCfg.Command,
+		model:       providerCfg.Model,
+		promptInput: promptInput,
+		output:      output,
+		outputField: providerCfg.OutputField,
+	}, nil
+}
+
+func (c *commandClient) GenerateCommitMessageStream(ctx context.Context, systemPrompt, userContent string) (Stream, error) {
+	prompt := openCodeCLIPrompt(systemPrompt, userContent)
+	spec := commandSpec{
+		name: c.argv[0],
+		scan: c.scanner(),
+	}
+
+	promptFile := ""
+	if c.promptInput == config.CommandPromptFile {
+		path, err := writeCommandPromptFile(prompt)
+		if err != nil {
+			return nil, err
+		}
+		promptFile = path
+		spec.cleanup = func() { _ = os.Remove(path) }
+	} else {
+		spec.stdin = prompt
+	}
+	spec.args = commandArgs(c.argv[1:], c.model, promptFile)
+
+	return startCommandStream(ctx, spec)
+}
+
+func commandArgs(template []string, model, promptFile string) []string {
+	args := make([]string, 0, len(template)+1)
+	usedPromptFile := false
+	for _, arg := range template {
+		if strings.Contains(arg, commandPromptFilePlaceholder) {
+			usedPromptFile = true
+		}
+		arg = strings.ReplaceAll(arg, commandModelPlaceholder, model)
+		arg = strings.ReplaceAll(arg, commandPromptFilePlaceholder, promptFile)
+		args = append(args, arg)
+	}
+	if promptFile != "" && !usedPromptFile {
+		args = append(args, promptFile)
+	}
+	return args
+}
+
+func writeCommandPromptFile(prompt string) (string, error) {
+	file, err := os.CreateTemp("", "yawn-prompt-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create prompt file: %w", err)
+	}
+	path := file.Name()
+	if _, err := file.WriteString(prompt); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		return "", fmt.Errorf("failed to write prompt file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(path)
+		return "", fmt.Errorf("failed to write prompt file: %w", err)
+	}
+	return path, nil
+}
+
+func (c *commandClient) scanner() func(ctx context.Context, output io.Reader, ch chan<- streamResult) error {
+	switch c.output {
+	case config.CommandOutputJSONLines:
+		return func(ctx context.Context, output io.Reader, ch chan<- streamResult) error {
+			return scanCommandJSONLines(ctx, output, ch, c.outputField)
+		}
+	case config.CommandOutputSSE:
+		parse := parseGeminiChatEvent
+		if c.outputField != "" {
+			parse = func(_ string, data []byte) (string, bool, error) {
+				text, err := extractJSONField(data, c.outputField)
+				return text, false, err
+			}
+		}
+		return func(ctx context.Context, output io.Reader, ch chan<- streamResult) error {
+			scanSSE(ctx, output, ch, parse)
+			return nil
+		}
+	default:
+		return scanCommandText
+	}
+}
+
+func scanCommandText(ctx context.Context, output io.Reader, ch chan<- streamResult) error {
+	reader := bufio.NewReader(output)
+	buf := make([]byte, 4096)
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			sendStreamResult(ctx, ch, streamResult{text: string(buf[:n])})
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func scanCommandJSONLines(ctx context.Context, output io.Reader, ch chan<- streamResult, field string) error {
+	scanner := bufio.NewScanner(output)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		text, err := extractJSONField([]byte(line), field)
+		if err != nil {
+			return err
+		}
+		if text != "" {
+			sendStreamResult(ctx, ch, streamResult{text: text})
+		}
+	}
+	return scanner.Err()
+}
+
+func extractJSONField(data []byte, field string) (string, error) {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "", fmt.Errorf("failed to parse command JSON output: %w", err)
+	}
+	for _, key := range splitJSONFieldPath(field) {
+		switch node := value.(type) {
+		case map[string]any:
+			value = node[key]
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return "", nil
+			}
+			value = node[index]
+		default:
+			return "", nil
+		}
+	}
+	text, _ := value.(string)
+	return text, nil
+}
+
+func splitJSONFieldPath(field string) []string {
+	field = strings.TrimPrefix(strings.TrimSpace(field), ".")
+	field = strings.NewReplacer("[", ".", "]", "").Replace(field)
+	var keys []string
+	for _, key := range strings.Split(field, ".") {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,108 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+type commandSpec struct {
+	name      string
+	args      []string
+	stdin     string
+	scan      func(ctx context.Context, output io.Reader, ch chan<- streamResult) error
+	wrapError func(err error, stderr string) error
+	cleanup   func()
+}
+
+type commandStream struct {
+	ch  <-chan streamResult
+	ctx context.Context
+}
+
+func startCommandStream(ctx context.Context, spec commandSpec) (Stream, error) {
+	cmd := exec.CommandContext(ctx, spec.name, spec.args...)
+	if spec.stdin != "" {
+		cmd.Stdin = strings.NewReader(spec.stdin)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		spec.runCleanup()
+		return nil, fmt.Errorf("failed to capture %s output: %w", spec.name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		spec.runCleanup()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, spec.commandError(err, stderr.String())
+	}
+
+	ch := make(chan streamResult, 16)
+	go func() {
+		defer close(ch)
+		defer spec.runCleanup()
+		scanErr := spec.scan(ctx, stdout, ch)
+		if scanErr != nil {
+			_ = cmd.Process.Kill()
+		} else {
+			_, _ = io.Copy(io.Discard, stdout)
+		}
+		waitErr := cmd.Wait()
+		if ctx.Err() != nil {
+			return
+		}
+		if scanErr != nil {
+			sendStreamResult(ctx, ch, streamResult{err: scanErr})
+			return
+		}
+		if waitErr != nil {
+			sendStreamResult(ctx, ch, streamResult{err: spec.commandError(waitErr, stderr.String())})
+		}
+	}()
+
+	return &commandStream{ch: ch, ctx: ctx}, nil
+}
+
+func (spec commandSpec) commandError(err error, stderr string) error {
+	if spec.wrapError != nil {
+		return spec.wrapError(err, stderr)
+	}
+	detail := strings.TrimSpace(stderr)
+	if detail == "" {
+		return fmt.Errorf("%s failed: %w", spec.name, err)
+	}
+	return fmt.Errorf("%s failed: %w: %s", spec.name, err, detail)
+}
+
+func (spec commandSpec) runCleanup() {
+	if spec.cleanup != nil {
+		spec.cleanup()
+	}
+}
+
+func (s *commandStream) Collect(onChunk func(string)) (string, error) {
+	var sb strings.Builder
+	for result := range s.ch {
+		if result.err != nil {
+			return "", result.err
+		}
+		if result.text == "" {
+			continue
+		}
+		if onChunk != nil {
+			onChunk(result.text)
+		}
+		sb.WriteString(result.text)
+	}
+	if s.ctx != nil && s.ctx.Err() != nil {
+		return "", s.ctx.Err()
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
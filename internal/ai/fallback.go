@@ -1,53 +1,124 @@
 package ai
 
-import "context"
+import (
+	"context"
+	"time"
+)
+
+type FailoverReporter func(failedProvider string, err error, nextProvider string)
+
+type failoverReporterKey struct{}
+
+func WithFailoverReporter(ctx context.Context, reporter FailoverReporter) context.Context {
+	return context.WithValue(ctx, failoverReporterKey{}, reporter)
+}
+
+func reportFailover(ctx context.Context, failedProvider string, err error, nextProvider string) {
+	if reporter, ok := ctx.Value(failoverReporterKey{}).(FailoverReporter); ok && reporter != nil {
+		reporter(failedProvider, err, nextProvider)
+	}
+}
+
+type fallbackLink struct {
+	provider string
+	timeout  time.Duration
+	client   func() (Client, error)
+}
 
 type fallbackClient struct {
-	primary  Client
-	fallback func() (Client, error)
+	links []fallbackLink
 }
 
 type fallbackStream struct {
-	primary  Stream
-	fallback func() (Stream, error)
+	ctx          context.Context
+	links        []fallbackLink
+	systemPrompt string
+	userContent  string
+	next         int
+	provider     string
+	current      Stream
+	cancel       context.CancelFunc
 }
 
-func (c *fallbackClient) GenerateCommitMessageStream(ctx context.Context, systemPrompt, userContent string) (Stream, error) {
-	primaryStream, err := c.primary.GenerateCommitMessageStream(ctx, systemPrompt, userContent)
+func (l fallbackLink) start(ctx context.Context, systemPrompt, userContent string) (Stream, context.CancelFunc, error) {
+	client, err := l.client()
 	if err != nil {
-		fallback, fallbackErr := c.fallback()
-		if fallbackErr != nil {
-			return nil, err
+		return nil, nil, err
+	}
+	for retry := 0; ; retry++ {
+		linkCtx, cancel := ctx, context.CancelFunc(func() {})
+		if l.timeout > 0 {
+			linkCtx, cancel = context.WithTimeout(ctx, l.timeout)
+		}
+		stream, err := client.GenerateCommitMessageStream(linkCtx, systemPrompt, userContent)
+		if err == nil {
+			return stream, cancel, nil
+		}
+		cancel()
+		wait, ok := rateLimitWait(ctx, err)
+		if !ok || retry >= maxRateLimitRetries {
+			return nil, nil, err
+		}
+		reportRateLimit(ctx, l.provider, wait)
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, nil, err
 		}
-		return fallback.GenerateCommitMessageStream(ctx, systemPrompt, userContent)
 	}
-	return &fallbackStream{
-		primary: primaryStream,
-		fallback: func() (Stream, error) {
-			fallback, err := c.fallback()
-			if err != nil {
-				return nil, err
-			}
-			return fallback.GenerateCommitMessageStream(ctx, systemPrompt, userContent)
-		},
-	}, nil
+}
+
+func (c *fallbackClient) GenerateCommitMessageStream(ctx context.Context, systemPrompt, userContent string) (Stream, error) {
+	stream := &fallbackStream{
+		ctx:          ctx,
+		links:        c.links,
+		systemPrompt: systemPrompt,
+		userContent:  userContent,
+	}
+	if err := stream.startNext(); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (s *fallbackStream) startNext() error {
+	var lastErr error
+	for s.next < len(s.links) {
+		link := s.links[s.next]
+		s.next++
+		stream, cancel, err := link.start(s.ctx, s.systemPrompt, s.userContent)
+		if err == nil {
+			s.provider = link.provider
+			s.current = stream
+			s.cancel = cancel
+			return nil
+		}
+		lastErr = err
+		if s.ctx.Err() != nil {
+			return lastErr
+		}
+		s.reportFailure(link.provider, err)
+	}
+	return lastErr
+}
+
+func (s *fallbackStream) reportFailure(provider string, err error) {
+	if s.next < len(s.links) {
+		reportFailover(s.ctx, provider, err, s.links[s.next].provider)
+	}
 }
 
 func (s *fallbackStream) Collect(onChunk func(string)) (string, error) {
-	emitted := false
-	message, err := s.primary.Collect(func(chunk string) {
-		emitted = true
-		onChunk(chunk)
-	})
-	if err == nil {
-		return message, nil
-	}
-	if emitted {
-		return "", err
-	}
-	stream, fallbackErr := s.fallback()
-	if fallbackErr != nil {
-		return "", err
-	}
-	return stream.Collect(onChunk)
+	for {
+		message, err := s.current.Collect(onChunk)
+		s.cancel()
+		if err == nil {
+			return message, nil
+		}
+		if s.next >= len(s.links) || s.ctx.Err() != nil {
+			return "", err
+		}
+		s.reportFailure(s.provider, err)
+		if startErr := s.startNext(); startErr != nil {
+			return "", startErr
+		}
+	}
 }
@@ -0,0 +1,236 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubStream struct {
+	chunks []string
+	err    error
+}
+
+func (s stubStream) Collect(onChunk func(string)) (string, error) {
+	message := ""
+	for _, chunk := range s.chunks {
+		onChunk(chunk)
+		message += chunk
+	}
+	if s.err != nil {
+		return "", s.err
+	}
+	return message, nil
+}
+
+type stubClient struct {
+	stream   Stream
+	startErr error
+	calls    int
+}
+
+func (c *stubClient) GenerateCommitMessageStream(ctx context.Context, systemPrompt, userContent string) (Stream, error) {
+	c.calls++
+	if c.startErr != nil {
+		return nil, c.startErr
+	}
+	return c.stream, nil
+}
+
+type funcClient func(ctx context.Context, systemPrompt, userContent string) (Stream, error)
+
+func (f funcClient) GenerateCommitMessageStream(ctx context.Context, systemPrompt, userContent string) (Stream, error) {
+	return f(ctx, systemPrompt, userContent)
+}
+
+func stubLink(provider string, client Client) fallbackLink {
+	return fallbackLink{provider: provider, client: func() (Client, error) { return client, nil }}
+}
+
+type failoverEvent struct {
+	failed string
+	next   string
+}
+
+func collectFailovers(events *[]failoverEvent) context.Context {
+	return WithFailoverReporter(context.Background(), func(failedProvider string, err error, nextProvider string) {
+		*events = append(*events, failoverEvent{failed: failedProvider, next: nextProvider})
+	})
+}
+
+func TestFallbackClientTriesLinksInOrder(t *testing.T) {
+	ollama := &stubClient{startErr: errors.New("connection refused")}
+	gemini := &stubClient{stream: stubStream{err: statusError{StatusCode: 503}}}
+	opencode := &stubClient{stream: stubStream{chunks: []string{"fix: ", "chain"}}}
+	client := &fallbackClient{links: []fallbackLink{
+		stubLink("ollama", ollama),
+		stubLink("gemini", gemini),
+		stubLink("opencode_cli", opencode),
+	}}
+	var events []failoverEvent
+
+	stream, err := client.GenerateCommitMessageStream(collectFailovers(&events), "", "")
+	require.NoError(t, err)
+	message, err := stream.Collect(func(string) {})
+
+	require.NoError(t, err)
+	assert.Equal(t, "fix: chain", message)
+	assert.Equal(t, []failoverEvent{{"ollama", "gemini"}, {"gemini", "opencode_cli"}}, events)
+	assert.Equal(t, 1, opencode.calls)
+}
+
+func TestFallbackClientReturnsLastErrorWhenAllLinksFail(t *testing.T) {
+	lastErr := errors.New("last failed")
+	client := &fallbackClient{links: []fallbackLink{
+		stubLink("a", &stubClient{startErr: errors.New("first failed")}),
+		{provider: "b", client: func() (Client, error) { return nil, lastErr }},
+	}}
+	var events []failoverEvent
+
+	_, err := client.GenerateCommitMessageStream(collectFailovers(&events), "", "")
+
+	assert.ErrorIs(t, err, lastErr)
+	assert.Equal(t, []failoverEvent{{"a", "b"}}, events)
+}
+
+func TestFallbackClientFailsOverAfterEmission(t *testing.T) {
+	client := &fallbackClient{links: []fallbackLink{
+		stubLink("a", &stubClient{stream: stubStream{chunks: []string{"fix: "}, err: io.ErrUnexpectedEOF}}),
+		stubLink("b", &stubClient{stream: stubStream{chunks: []string{"fix: ", "retry"}}}),
+	}}
+	var events []failoverEvent
+
+	stream, err := client.GenerateCommitMessageStream(collectFailovers(&events), "", "")
+	require.NoError(t, err)
+	var chunks []string
+	message, err := stream.Collect(func(chunk string) { chunks = append(chunks, chunk) })
+
+	require.NoError(t, err)
+	assert.Equal(t, "fix: retry", message)
+	assert.Equal(t, []string{"fix: ", "fix: ", "retry"}, chunks)
+	assert.Equal(t, []failoverEvent{{"a", "b"}}, events)
+}
+
+func TestFallbackClientReturnsMidStreamErrorOnLastLink(t *testing.T) {
+	client := &fallbackClient{links: []fallbackLink{
+		stubLink("a", &stubClient{stream: stubStream{chunks: []string{"fix: "}, err: errors.New("reset")}}),
+	}}
+
+	stream, err := client.GenerateCommitMessageStream(context.Background(), "", "")
+	require.NoError(t, err)
+	_, err = stream.Collect(func(string) {})
+
+	assert.ErrorContains(t, err, "reset")
+}
+
+func TestFallbackLinkTimeoutCancelsSlowProvider(t *testing.T) {
+	slow := funcClient(func(ctx context.Context, systemPrompt, userContent string) (Stream, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	client := &fallbackClient{links: []fallbackLink{
+		{provider: "slow", timeout: 10 * time.Millisecond, client: func() (Client, error) { return slow, nil }},
+		stubLink("fast", &stubClient{stream: stubStream{chunks: []string{"fix: fast"}}}),
+	}}
+
+	stream, err := client.GenerateCommitMessageStream(context.Background(), "", "")
+	require.NoError(t, err)
+	message, err := stream.Collect(func(string) {})
+
+	require.NoError(t, err)
+	assert.Equal(t, "fix: fast", message)
+}
+
+func blockingClient(cancelled chan<- struct{}) funcClient {
+	return funcClient(func(ctx context.Context, systemPrompt, userContent string) (Stream, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	})
+}
+
+func TestHedgedClientRacesNextProviderAfterWindow(t *testing.T) {
+	cancelled := make(chan struct{})
+	client := &hedgedClient{hedgeAfter: 10 * time.Millisecond, links: []fallbackLink{
+		{provider: "gemini", client: func() (Client, error) { return blockingClient(cancelled), nil }},
+		stubLink("ollama", &stubClient{stream: stubStream{chunks: []string{"fix: ", "hedged"}}}),
+	}}
+	var events []failoverEvent
+
+	stream, err := client.GenerateCommitMessageStream(collectFailovers(&events), "", "")
+	require.NoError(t, err)
+	var chunks []string
+	message, err := stream.Collect(func(chunk string) { chunks = append(chunks, chunk) })
+
+	require.NoError(t, err)
+	assert.Equal(t, "fix: hedged", message)
+	assert.Equal(t, []string{"fix: ", "hedged"}, chunks)
+	assert.Equal(t, []failoverEvent{{"gemini", "ollama"}}, events)
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("losing provider was not cancelled")
+	}
+}
+
+func TestHedgedClientKeepsFastPrimary(t *testing.T) {
+	backup := &stubClient{stream: stubStream{chunks: []string{"unused"}}}
+	client := &hedgedClient{hedgeAfter: time.Second, links: []fallbackLink{
+		stubLink("gemini", &stubClient{stream: stubStream{chunks: []string{"fix: primary"}}}),
+		stubLink("ollama", backup),
+	}}
+
+	stream, err := client.GenerateCommitMessageStream(context.Background(), "", "")
+	require.NoError(t, err)
+	message, err := stream.Collect(func(string) {})
+
+	require.NoError(t, err)
+	assert.Equal(t, "fix: primary", message)
+	assert.Equal(t, 0, backup.calls)
+}
+
+func TestHedgedClientFailsOverImmediatelyOnError(t *testing.T) {
+	client := &hedgedClient{hedgeAfter: time.Hour, links: []fallbackLink{
+		stubLink("gemini", &stubClient{startErr: statusError{StatusCode: 503}}),
+		stubLink("ollama", &stubClient{stream: stubStream{chunks: []string{"fix: backup"}}}),
+	}}
+
+	stream, err := client.GenerateCommitMessageStream(context.Background(), "", "")
+	require.NoError(t, err)
+	message, err := stream.Collect(func(string) {})
+
+	require.NoError(t, err)
+	assert.Equal(t, "fix: backup", message)
+}
+
+func TestHedgedClientReturnsErrorWhenAllFail(t *testing.T) {
+	client := &hedgedClient{hedgeAfter: time.Hour, links: []fallbackLink{
+		stubLink("gemini", &stubClient{startErr: errors.New("first")}),
+		stubLink("ollama", &stubClient{startErr: errors.New("second")}),
+	}}
+
+	_, err := client.GenerateCommitMessageStream(context.Background(), "", "")
+
+	assert.ErrorContains(t, err, "second")
+}
+
+func TestHedgedClientTakesOverMidStream(t *testing.T) {
+	client := &hedgedClient{hedgeAfter: time.Hour, links: []fallbackLink{
+		stubLink("gemini", &stubClient{stream: stubStream{chunks: []string{"fix: "}, err: io.ErrUnexpectedEOF}}),
+		stubLink("ollama", &stubClient{stream: stubStream{chunks: []string{"fix: backup"}}}),
+	}}
+	var events []failoverEvent
+
+	stream, err := client.GenerateCommitMessageStream(collectFailovers(&events), "", "")
+	require.NoError(t, err)
+	message, err := stream.Collect(func(string) {})
+
+	require.NoError(t, err)
+	assert.Equal(t, "fix: backup", message)
+	assert.Equal(t, []failoverEvent{{"gemini", "ollama"}}, events)
+}
@@ -51,7 +51,7 @@ func (c *geminiClient) GenerateCommitMessageStream(ctx context.Context, systemPr
 	}, nil, parseGeminiChatEvent)
 }
 
-func parseGeminiChatEvent(data []byte) (string, bool, error) {
+func parseGeminiChatEvent(_ string, data []byte) (string, bool, error) {
 	var event geminiChatEvent
 	if err := json.Unmarshal(data, &event); err != nil {
 		return "", false, err
@@ -60,11 +60,8 @@ func parseGeminiChatEvent(data []byte) (string, bool, error) {
 		return "", false, fmt.Errorf("provider stream error: %s", event.Error.Message)
 	}
 	for _, choice := range event.Choices {
-		if choice.Delta.Content != "" {
-			return choice.Delta.Content, false, nil
-		}
-		if choice.FinishReason != nil {
-			return "", true, nil
+		if choice.Delta.Content != "" || choice.FinishReason != nil {
+			return choice.Delta.Content, choice.FinishReason != nil, nil
 		}
 	}
 	return "", false, nil
@@ -0,0 +1,185 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrHedgeWindowElapsed = errors.New("no output within hedge window")
+
+type hedgedClient struct {
+	links      []fallbackLink
+	hedgeAfter time.Duration
+}
+
+type hedgeEvent struct {
+	attempt int
+	chunk   string
+	done    bool
+	message string
+	err     error
+}
+
+type hedgeAttempt struct {
+	provider string
+	cancel   context.CancelFunc
+	active   bool
+}
+
+type hedgedStream struct {
+	ctx          context.Context
+	links        []fallbackLink
+	systemPrompt string
+	userContent  string
+	hedgeAfter   time.Duration
+	events       chan hedgeEvent
+	attempts     []hedgeAttempt
+	running      int
+	next         int
+	winner       int
+	first        hedgeEvent
+}
+
+func (c *hedgedClient) GenerateCommitMessageStream(ctx context.Context, systemPrompt, userContent string) (Stream, error) {
+	stream := &hedgedStream{
+		ctx:          ctx,
+		links:        c.links,
+		systemPrompt: systemPrompt,
+		userContent:  userContent,
+		hedgeAfter:   c.hedgeAfter,
+		events:       make(chan hedgeEvent),
+		winner:       -1,
+	}
+	if err := stream.race(); err != nil {
+		stream.cancelAll()
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (s *hedgedStream) race() error {
+	s.launchNext()
+	timer := time.NewTimer(s.hedgeAfter)
+	defer timer.Stop()
+
+	var lastErr error
+	for {
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case <-timer.C:
+			if s.next < len(s.links) {
+				slow := s.attempts[len(s.attempts)-1].provider
+				reportFailover(s.ctx, slow, fmt.Errorf("no output after %s: %w", s.hedgeAfter, ErrHedgeWindowElapsed), s.links[s.next].provider)
+				s.launchNext()
+				timer.Reset(s.hedgeAfter)
+			}
+		case event := <-s.events:
+			if !s.attempts[event.attempt].active {
+				continue
+			}
+			if event.chunk != "" || (event.done && event.err == nil) {
+				s.win(event)
+				return nil
+			}
+			s.attempts[event.attempt].active = false
+			s.running--
+			lastErr = event.err
+			if s.next < len(s.links) {
+				reportFailover(s.ctx, s.attempts[event.attempt].provider, event.err, s.links[s.next].provider)
+				s.launchNext()
+				timer.Reset(s.hedgeAfter)
+			} else if s.running == 0 {
+				return lastErr
+			}
+		}
+	}
+}
+
+func (s *hedgedStream) launchNext() {
+	link := s.links[s.next]
+	s.next++
+	ctx, cancel := context.WithCancel(s.ctx)
+	attempt := len(s.attempts)
+	s.attempts = append(s.attempts, hedgeAttempt{provider: link.provider, cancel: cancel, active: true})
+	s.running++
+	go s.run(ctx, attempt, link)
+}
+
+func (s *hedgedStream) run(ctx context.Context, attempt int, link fallbackLink) {
+	stream, cancel, err := link.start(ctx, s.systemPrompt, s.userContent)
+	if err != nil {
+		s.send(ctx, hedgeEvent{attempt: attempt, done: true, err: err})
+		return
+	}
+	defer cancel()
+	message, err := stream.Collect(func(chunk string) {
+		s.send(ctx, hedgeEvent{attempt: attempt, chunk: chunk})
+	})
+	s.send(ctx, hedgeEvent{attempt: attempt, done: true, message: message, err: err})
+}
+
+func (s *hedgedStream) send(ctx context.Context, event hedgeEvent) {
+	select {
+	case s.events <- event:
+	case <-ctx.Done():
+	}
+}
+
+func (s *hedgedStream) win(event hedgeEvent) {
+	s.winner = event.attempt
+	s.first = event
+	s.running = 1
+	for i := range s.attempts {
+		if i != s.winner && s.attempts[i].active {
+			s.attempts[i].active = false
+			s.attempts[i].cancel()
+		}
+	}
+}
+
+func (s *hedgedStream) takeOver(err error) error {
+	failed := s.attempts[s.winner].provider
+	s.attempts[s.winner].active = false
+	s.attempts[s.winner].cancel()
+	s.running = 0
+	s.winner = -1
+	reportFailover(s.ctx, failed, err, s.links[s.next].provider)
+	return s.race()
+}
+
+func (s *hedgedStream) cancelAll() {
+	for _, attempt := range s.attempts {
+		attempt.cancel()
+	}
+}
+
+func (s *hedgedStream) Collect(onChunk func(string)) (string, error) {
+	defer s.cancelAll()
+	event := s.first
+	for {
+		if event.attempt == s.winner {
+			if event.done && event.err != nil && s.next < len(s.links) && s.ctx.Err() == nil {
+				if err := s.takeOver(event.err); err != nil {
+					return "", err
+				}
+				event = s.first
+				continue
+			}
+			if event.done {
+				if event.err != nil {
+					return "", event.err
+				}
+				return event.message, nil
+			}
+			onChunk(event.chunk)
+		}
+		select {
+		case <-s.ctx.Done():
+			return "", s.ctx.Err()
+		case event = <-s.events:
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 )
 
 const maxErrorBodyBytes = 4096
@@ -25,7 +26,9 @@ type httpStream struct {
 	ctx context.Context
 }
 
-type streamParser func([]byte) (string, bool, error)
+type streamParser func(event string, data []byte) (string, bool, error)
+
+type streamScanner func(ctx context.Context, body io.Reader, ch chan<- streamResult, parse streamParser)
 
 func (s *httpStream) Collect(onChunk func(string)) (string, error) {
 	var sb strings.Builder
@@ -46,6 +49,10 @@ func (s *httpStream) Collect(onChunk func(string)) (string, error) {
 }
 
 func startJSONStream(ctx context.Context, endpoint, apiKey string, payload any, headers map[string]string, parse streamParser) (Stream, error) {
+	return startHTTPStream(ctx, endpoint, apiKey, payload, headers, "text/event-stream", scanSSE, parse)
+}
+
+func startHTTPStream(ctx context.Context, endpoint, apiKey string, payload any, headers map[string]string, accept string, scan streamScanner, parse streamParser) (Stream, error) {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode request: %w", err)
@@ -55,9 +62,11 @@ func startJSONStream(ctx context.Context, endpoint, apiKey string, payload any,
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Accept", accept)
 	for key, value := range headers {
 		req.Header.Set(key, value)
 	}
@@ -69,14 +78,18 @@ func startJSONStream(ctx context.Context, endpoint, apiKey string, payload any,
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
 		defer func() { _ = resp.Body.Close() }()
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
-		return nil, statusError{StatusCode: resp.StatusCode, Body: string(body)}
+		return nil, statusError{
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: parseRetryAfter(resp.Header, string(body), time.Now()),
+		}
 	}
 
 	ch := make(chan streamResult, 16)
 	go func() {
 		defer close(ch)
 		defer func() { _ = resp.Body.Close() }()
-		scanSSE(ctx, resp.Body, ch, parse)
+		scan(ctx, resp.Body, ch, parse)
 	}()
 
 	return &httpStream{ch: ch, ctx: ctx}, nil
@@ -85,16 +98,25 @@ func startJSONStream(ctx context.Context, endpoint, apiKey string, payload any,
 func scanSSE(ctx context.Context, body io.Reader, ch chan<- streamResult, parse streamParser) {
 	scanner := bufio.NewScanner(body)
 	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	event := ""
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, ":") || !strings.HasPrefix(line, "data:") {
+		if line == "" {
+			event = ""
+			continue
+		}
+		if strings.HasPrefix(line, "event:") {
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+			continue
+		}
+		if strings.HasPrefix(line, ":") || !strings.HasPrefix(line, "data:") {
 			continue
 		}
 		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
 		if data == "[DONE]" {
 			return
 		}
-		text, done, err := parse([]byte(data))
+		text, done, err := parse(event, []byte(data))
 		if err != nil {
 			sendStreamResult(ctx, ch, streamResult{err: err})
 			return
@@ -106,9 +128,37 @@ func scanSSE(ctx context.Context, body io.Reader, ch chan<- streamResult, parse
 			return
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		sendStreamResult(ctx, ch, streamResult{err: err})
+	sendScanEnd(ctx, ch, scanner.Err())
+}
+
+func scanNDJSON(ctx context.Context, body io.Reader, ch chan<- streamResult, parse streamParser) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		text, done, err := parse("", line)
+		if err != nil {
+			sendStreamResult(ctx, ch, streamResult{err: err})
+			return
+		}
+		if text != "" {
+			sendStreamResult(ctx, ch, streamResult{text: text})
+		}
+		if done {
+			return
+		}
+	}
+	sendScanEnd(ctx, ch, scanner.Err())
+}
+
+func sendScanEnd(ctx context.Context, ch chan<- streamResult, err error) {
+	if err == nil {
+		err = fmt.Errorf("stream ended before the end marker: %w", io.ErrUnexpectedEOF)
 	}
+	sendStreamResult(ctx, ch, streamResult{err: err})
 }
 
 func sendStreamResult(ctx context.Context, ch chan<- streamResult, result streamResult) {
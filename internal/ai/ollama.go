@@ -0,0 +1,79 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const ollamaChatPath = "/api/chat"
+
+type ollamaClient struct {
+	endpoint  string
+	model     string
+	keepAlive string
+	numCtx    int
+}
+
+type ollamaChatRequest struct {
+	Model     string              `json:"model"`
+	Messages  []geminiChatMessage `json:"messages"`
+	Stream    bool                `json:"stream"`
+	KeepAlive string              `json:"keep_alive,omitempty"`
+	Options   ollamaChatOptions   `json:"options"`
+}
+
+type ollamaChatOptions struct {
+	Temperature float32 `json:"temperature"`
+	NumCtx      int     `json:"num_ctx,omitempty"`
+}
+
+type ollamaChatEvent struct {
+	Message struct {
+		Content string `json:"content"`
+	} `json:"message"`
+	Done  bool   `json:"done"`
+	Error string `json:"error"`
+}
+
+func newOllamaClient(host, model, keepAlive string, numCtx int) *ollamaClient {
+	return &ollamaClient{
+		endpoint:  ollamaEndpoint(host),
+		model:     model,
+		keepAlive: keepAlive,
+		numCtx:    numCtx,
+	}
+}
+
+func (c *ollamaClient) GenerateCommitMessageStream(ctx context.Context, systemPrompt, userContent string) (Stream, error) {
+	return startHTTPStream(ctx, c.endpoint, "", ollamaChatRequest{
+		Model: c.model,
+		Messages: []geminiChatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userContent},
+		},
+		Stream:    true,
+		KeepAlive: c.keepAlive,
+		Options:   ollamaChatOptions{Temperature: 0, NumCtx: c.numCtx},
+	}, nil, "application/x-ndjson", scanNDJSON, parseOllamaChatEvent)
+}
+
+func ollamaEndpoint(host string) string {
+	host = strings.TrimRight(strings.TrimSpace(host), "/")
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+	return host + ollamaChatPath
+}
+
+func parseOllamaChatEvent(_ string, data []byte) (string, bool, error) {
+	var event ollamaChatEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return "", false, err
+	}
+	if event.Error != "" {
+		return "", false, fmt.Errorf("ollama error: %s", event.Error)
+	}
+	return event.Message.Content, event.Done, nil
+}
@@ -0,0 +1,44 @@
+package ai
+
+import (
+	"context"
+	"strings"
+)
+
+const openAICompatibleChatCompletionsPath = "/chat/completions"
+
+type openAICompatibleClient struct {
+	endpoint string
+	apiKey   string
+	model    string
+	headers  map[string]string
+}
+
+func newOpenAICompatibleClient(baseURL, apiKey, model string, headers map[string]string) *openAICompatibleClient {
+	return &openAICompatibleClient{
+		endpoint: openAICompatibleEndpoint(baseURL),
+		apiKey:   apiKey,
+		model:    model,
+		headers:  headers,
+	}
+}
+
+func (c *openAICompatibleClient) GenerateCommitMessageStream(ctx context.Context, systemPrompt, userContent string) (Stream, error) {
+	return startJSONStream(ctx, c.endpoint, c.apiKey, geminiChatRequest{
+		Model: c.model,
+		Messages: []geminiChatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userContent},
+		},
+		Stream:      true,
+		Temperature: 0,
+	}, c.headers, parseGeminiChatEvent)
+}
+
+func openAICompatibleEndpoint(baseURL string) string {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if strings.HasSuffix(baseURL, openAICompatibleChatCompletionsPath) {
+		return baseURL
+	}
+	return baseURL + openAICompatibleChatCompletionsPath
+}
@@ -7,21 +7,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"os/exec"
 	"strings"
 )
 
-const openCodeCLILowReasoningVariant = "low"
+const (
+	openCodeCLILowReasoningVariant = "low"
+	cliPromptInstruction           = "Do not use tools. Do not include reasoning, analysis, or commentary. Return only the requested commit message."
+)
 
 type openCodeCLIClient struct {
 	model string
 }
 
-type openCodeCLIStream struct {
-	ch  <-chan streamResult
-	ctx context.Context
-}
-
 type openCodeCLIEvent struct {
 	Type  string `json:"type"`
 	Error *struct {
@@ -41,61 +38,15 @@ func newOpenCodeCLIClient(model string) *openCodeCLIClient {
 }
 
 func (c *openCodeCLIClient) GenerateCommitMessageStream(ctx context.Context, systemPrompt, userContent string) (Stream, error) {
-	cmd := exec.CommandContext(ctx, "opencode", openCodeCLIArgs(c.model)...)
-	prompt := openCodeCLIPrompt(systemPrompt, userContent)
-	cmd.Stdin = strings.NewReader(prompt)
-
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return nil, fmt.Errorf("failed to capture opencode output: %w", err)
-	}
-	if err := cmd.Start(); err != nil {
-		if ctx.Err() != nil {
-			return nil, ctx.Err()
-		}
-		return nil, openCodeCLICommandError(err, stderr.String())
-	}
-
-	ch := make(chan streamResult, 16)
-	go func() {
-		defer close(ch)
-		scanErr := scanOpenCodeCLIOutput(ctx, stdout, ch)
-		waitErr := cmd.Wait()
-		if ctx.Err() != nil {
-			return
-		}
-		if scanErr != nil {
-			sendStreamResult(ctx, ch, streamResult{err: scanErr})
-			return
-		}
-		if waitErr != nil {
-			sendStreamResult(ctx, ch, streamResult{err: openCodeCLICommandError(waitErr, stderr.String())})
-		}
-	}()
-
-	return &openCodeCLIStream{ch: ch, ctx: ctx}, nil
-}
-
-func (s *openCodeCLIStream) Collect(onChunk func(string)) (string, error) {
-	var sb strings.Builder
-	for result := range s.ch {
-		if result.err != nil {
-			return "", result.err
-		}
-		if result.text == "" {
-			continue
-		}
-		if onChunk != nil {
-			onChunk(result.text)
-		}
-		sb.WriteString(result.text)
-	}
-	if s.ctx != nil && s.ctx.Err() != nil {
-		return "", s.ctx.Err()
-	}
-	return strings.TrimSpace(sb.String()), nil
+	return startCommandStream(ctx, commandSpec{
+		name:  "opencode",
+		args:  openCodeCLIArgs(c.model),
+		stdin: openCodeCLIPrompt(systemPrompt, userContent),
+		scan:  scanOpenCodeCLIOutput,
+		wrapError: func(err error, stderr string) error {
+			return openCodeCLICommandError(err, stderr)
+		},
+	})
 }
 
 func openCodeCLIArgs(model string) []string {
@@ -112,7 +63,7 @@ func openCodeCLIArgs(model string) []string {
 func openCodeCLIPrompt(systemPrompt, userContent string) string {
 	parts := []string{
 		strings.TrimSpace(systemPrompt),
-		"Do not use tools. Do not include reasoning, analysis, or commentary. Return only the requested commit message.",
+		cliPromptInstruction,
 		strings.TrimSpace(userContent),
 	}
 	return strings.Join(parts, "\n\n")
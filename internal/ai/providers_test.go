@@ -2,16 +2,24 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/Mayurifag/yawn/internal/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseGeminiChatEvent(t *testing.T) {
-	text, done, err := parseGeminiChatEvent([]byte(`{"choices":[{"delta":{"content":"fix"}}]}`))
+	text, done, err := parseGeminiChatEvent("", []byte(`{"choices":[{"delta":{"content":"fix"}}]}`))
 
 	assert.NoError(t, err)
 	assert.False(t, done)
@@ -19,7 +27,7 @@ func TestParseGeminiChatEvent(t *testing.T) {
 }
 
 func TestParseGeminiChatEventDone(t *testing.T) {
-	text, done, err := parseGeminiChatEvent([]byte(`{"choices":[{"delta":{},"finish_reason":"stop"}]}`))
+	text, done, err := parseGeminiChatEvent("", []byte(`{"choices":[{"delta":{},"finish_reason":"stop"}]}`))
 
 	assert.NoError(t, err)
 	assert.True(t, done)
@@ -82,7 +90,10 @@ func TestIsTransientError(t *testing.T) {
 		{"context deadline", context.DeadlineExceeded, true},
 		{"http 429", statusError{StatusCode: 429}, true},
 		{"http 503", statusError{StatusCode: 503}, true},
+		{"http 529 overloaded", statusError{StatusCode: 529}, true},
 		{"http 400", statusError{StatusCode: 400}, false},
+		{"unexpected eof", fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), true},
+		{"connection reset", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
 		{"plain", fmt.Errorf("failed"), false},
 	}
 
@@ -92,3 +103,304 @@ func TestIsTransientError(t *testing.T) {
 		})
 	}
 }
+
+func TestOpenAICompatibleEndpoint(t *testing.T) {
+	assert.Equal(t, "http://localhost:1234/v1/chat/completions", openAICompatibleEndpoint("http://localhost:1234/v1/"))
+	assert.Equal(t, "https://openrouter.ai/api/v1/chat/completions", openAICompatibleEndpoint("https://openrouter.ai/api/v1/chat/completions"))
+}
+
+func TestOpenAICompatibleClientStreamsFromBaseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer local-key", r.Header.Get("Authorization"))
+		assert.Equal(t, "yawn", r.Header.Get("X-Title"))
+		_, _ = fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"feat: \"}}]}\n\n")
+		_, _ = fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"local\"}}]}\n\n")
+		_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client, err := NewClient(config.Config{
+		MainProvider: "local",
+		Providers: map[string]config.ProviderConfig{
+			"local": {
+				Type:    config.ProviderOpenAICompat,
+				BaseURL: server.URL + "/v1",
+				APIKey:  "local-key",
+				Model:   "qwen2.5-coder",
+				Headers: map[string]string{"X-Title": "yawn"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	stream, err := client.GenerateCommitMessageStream(context.Background(), "system", "diff")
+	require.NoError(t, err)
+	message, err := stream.Collect(func(string) {})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "feat: local", message)
+}
+
+func TestNewClientRequiresOpenAICompatibleBaseURL(t *testing.T) {
+	_, err := NewClient(config.Config{
+		MainProvider: "router",
+		Providers: map[string]config.ProviderConfig{
+			"router": {Type: config.ProviderOpenAICompat, Model: "any"},
+		},
+	})
+
+	assert.ErrorContains(t, err, "base_url is required")
+}
+
+func TestParseOllamaChatEvent(t *testing.T) {
+	text, done, err := parseOllamaChatEvent("", []byte(`{"message":{"role":"assistant","content":"fix"},"done":false}`))
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, "fix", text)
+
+	_, done, err = parseOllamaChatEvent("", []byte(`{"message":{"role":"assistant","content":""},"done":true}`))
+	assert.NoError(t, err)
+	assert.True(t, done)
+
+	_, _, err = parseOllamaChatEvent("", []byte(`{"error":"model not found"}`))
+	assert.ErrorContains(t, err, "model not found")
+}
+
+func TestScannersRequireEndMarker(t *testing.T) {
+	tests := []struct {
+		name  string
+		scan  streamScanner
+		parse streamParser
+		body  string
+		err   bool
+	}{
+		{"sse done", scanSSE, parseGeminiChatEvent, "data: {\"choices\":[{\"delta\":{\"content\":\"fix\"}}]}\n\ndata: [DONE]\n\n", false},
+		{"sse finish reason", scanSSE, parseGeminiChatEvent, "data: {\"choices\":[{\"delta\":{\"content\":\"fix\"}}]}\n\ndata: {\"choices\":[{\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n", false},
+		{"sse content with finish reason", scanSSE, parseGeminiChatEvent, "data: {\"choices\":[{\"delta\":{\"content\":\"fix\"},\"finish_reason\":\"stop\"}]}\n\n", false},
+		{"sse cut off", scanSSE, parseGeminiChatEvent, "data: {\"choices\":[{\"delta\":{\"content\":\"fix\"}}]}\n\n", true},
+		{"anthropic cut off", scanSSE, parseAnthropicEvent, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"fix\"}}\n\n", true},
+		{"ndjson done", scanNDJSON, parseOllamaChatEvent, "{\"message\":{\"content\":\"fix\"}}\n{\"done\":true}\n", false},
+		{"ndjson cut off", scanNDJSON, parseOllamaChatEvent, "{\"message\":{\"content\":\"fix\"}}\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := make(chan streamResult, 16)
+			go func() {
+				defer close(ch)
+				tt.scan(context.Background(), strings.NewReader(tt.body), ch, tt.parse)
+			}()
+			message, err := (&httpStream{ch: ch}).Collect(func(string) {})
+
+			if tt.err {
+				assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+				assert.True(t, IsTransientError(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "fix", message)
+		})
+	}
+}
+
+func TestOllamaEndpoint(t *testing.T) {
+	assert.Equal(t, "http://localhost:11434/api/chat", ollamaEndpoint(config.DefaultOllamaHost))
+	assert.Equal(t, "http://gpu-box:11434/api/chat", ollamaEndpoint("gpu-box:11434/"))
+}
+
+func TestOllamaClientStreamsNDJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaChatRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "/api/chat", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"))
+		assert.Equal(t, "llama3.2", req.Model)
+		assert.Equal(t, "10m", req.KeepAlive)
+		assert.Equal(t, 16384, req.Options.NumCtx)
+		_, _ = fmt.Fprint(w, "{\"message\":{\"content\":\"chore: \"},\"done\":false}\n")
+		_, _ = fmt.Fprint(w, "{\"message\":{\"content\":\"offline\"},\"done\":false}\n")
+		_, _ = fmt.Fprint(w, "{\"message\":{\"content\":\"\"},\"done\":true}\n")
+	}))
+	defer server.Close()
+
+	client, err := NewClient(config.Config{
+		MainProvider: config.ProviderOllama,
+		Providers: map[string]config.ProviderConfig{
+			config.ProviderOllama: {Host: server.URL, Model: "llama3.2", KeepAlive: "10m", NumCtx: 16384},
+		},
+	})
+	require.NoError(t, err)
+
+	stream, err := client.GenerateCommitMessageStream(context.Background(), "system", "diff")
+	require.NoError(t, err)
+	message, err := stream.Collect(func(string) {})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "chore: offline", message)
+}
+
+func TestParseAnthropicEvent(t *testing.T) {
+	text, done, err := parseAnthropicEvent("content_block_delta", []byte(`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"fix"}}`))
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, "fix", text)
+
+	text, done, err = parseAnthropicEvent("message_start", []byte(`{"type":"message_start","message":{}}`))
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, "", text)
+
+	_, done, err = parseAnthropicEvent("message_stop", []byte(`{"type":"message_stop"}`))
+	assert.NoError(t, err)
+	assert.True(t, done)
+
+	_, _, err = parseAnthropicEvent("error", []byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`))
+	assert.ErrorContains(t, err, "Overloaded")
+	assert.True(t, IsTransientError(err))
+}
+
+func TestAnthropicClientStreamsMessages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req anthropicMessagesRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "anthropic-key", r.Header.Get("x-api-key"))
+		assert.Equal(t, anthropicVersion, r.Header.Get("anthropic-version"))
+		assert.Empty(t, r.Header.Get("Authorization"))
+		assert.Equal(t, "system", req.System)
+		assert.Positive(t, req.MaxTokens)
+		_, _ = fmt.Fprint(w, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{}}\n\n")
+		_, _ = fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"docs: \"}}\n\n")
+		_, _ = fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"anthropic\"}}\n\n")
+		_, _ = fmt.Fprint(w, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+	}))
+	defer server.Close()
+
+	client, err := NewClient(config.Config{
+		MainProvider: config.ProviderAnthropic,
+		Providers: map[string]config.ProviderConfig{
+			config.ProviderAnthropic: {APIKey: "anthropic-key", BaseURL: server.URL},
+		},
+	})
+	require.NoError(t, err)
+
+	stream, err := client.GenerateCommitMessageStream(context.Background(), "system", "diff")
+	require.NoError(t, err)
+	message, err := stream.Collect(func(string) {})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "docs: anthropic", message)
+}
+
+func TestCommandArgsSubstitutesPlaceholders(t *testing.T) {
+	assert.Equal(t, []string{"-m", "gpt-4o-mini", "--file", "/tmp/prompt.txt"}, commandArgs([]string{"-m", "{model}", "--file", "{prompt_file}"}, "gpt-4o-mini", "/tmp/prompt.txt"))
+	assert.Equal(t, []string{"-p", "/tmp/prompt.txt"}, commandArgs([]string{"-p"}, "", "/tmp/prompt.txt"))
+	assert.Equal(t, []string{"-p"}, commandArgs([]string{"-p"}, "", ""))
+}
+
+func TestExtractJSONField(t *testing.T) {
+	line := []byte(`{"type":"text","part":{"text":"fix"},"choices":[{"delta":{"content":"feat"}}]}`)
+
+	text, err := extractJSONField(line, ".part.text")
+	assert.NoError(t, err)
+	assert.Equal(t, "fix", text)
+
+	text, err = extractJSONField(line, "choices[0].delta.content")
+	assert.NoError(t, err)
+	assert.Equal(t, "feat", text)
+
+	text, err = extractJSONField(line, "missing.field")
+	assert.NoError(t, err)
+	assert.Equal(t, "", text)
+
+	_, err = extractJSONField([]byte("not json"), "text")
+	assert.Error(t, err)
+}
+
+func TestNewCommandClientValidatesConfig(t *testing.T) {
+	_, err := newCommandClient(config.ProviderConfig{})
+	assert.ErrorContains(t, err, "command is required")
+
+	_, err = newCommandClient(config.ProviderConfig{Command: []string{"llm"}, Output: "xml"})
+	assert.ErrorContains(t, err, "unsupported output")
+
+	_, err = newCommandClient(config.ProviderConfig{Command: []string{"llm"}, Output: "json-lines"})
+	assert.ErrorContains(t, err, "output_field is required")
+
+	client, err := newCommandClient(config.ProviderConfig{Command: []string{"llm"}})
+	assert.NoError(t, err)
+	assert.Equal(t, config.CommandPromptStdin, client.promptInput)
+	assert.Equal(t, config.CommandOutputText, client.output)
+}
+
+func TestCommandClientStreamsJSONLinesFromPromptFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	client, err := NewClient(config.Config{
+		MainProvider: "wrapper",
+		Providers: map[string]config.ProviderConfig{
+			"wrapper": {
+				Type:        config.ProviderCommand,
+				Command:     []string{"sh", "-c", `grep -q "diff content" "$1" && printf '{"text":"refactor: "}\n{"meta":1}\n{"text":"wrapper"}\n'`, "sh", "{prompt_file}"},
+				PromptInput: config.CommandPromptFile,
+				Output:      config.CommandOutputJSONLines,
+				OutputField: "text",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	stream, err := client.GenerateCommitMessageStream(context.Background(), "system", "diff content")
+	require.NoError(t, err)
+	message, err := stream.Collect(func(string) {})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "refactor: wrapper", message)
+}
+
+func TestCommandClientStopsCommandOnParseError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	client, err := NewClient(config.Config{
+		MainProvider: "wrapper",
+		Providers: map[string]config.ProviderConfig{
+			"wrapper": {
+				Type:        config.ProviderCommand,
+				Command:     []string{"sh", "-c", `cat >/dev/null; echo 'not json'; exec yes '{"text":"x"}'`},
+				Output:      config.CommandOutputJSONLines,
+				OutputField: "text",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream, err := client.GenerateCommitMessageStream(ctx, "system", "diff")
+	require.NoError(t, err)
+	_, err = stream.Collect(func(string) {})
+
+	assert.ErrorContains(t, err, "failed to parse command JSON output")
+}
+
+func TestCommandClientReportsStderrOnFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	client, err := NewClient(config.Config{
+		MainProvider: "broken",
+		Providers: map[string]config.ProviderConfig{
+			"broken": {Type: config.ProviderCommand, Command: []string{"sh", "-c", "cat >/dev/null; echo 'no model' >&2; exit 3"}},
+		},
+	})
+	require.NoError(t, err)
+
+	stream, err := client.GenerateCommitMessageStream(context.Background(), "system", "diff")
+	require.NoError(t, err)
+	_, err = stream.Collect(func(string) {})
+
+	assert.ErrorContains(t, err, "sh failed")
+	assert.ErrorContains(t, err, "no model")
+}
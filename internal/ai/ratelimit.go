@@ -0,0 +1,109 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxRateLimitRetries = 2
+
+var retryDelayPattern = regexp.MustCompile(`"retryDelay"\s*:\s*"([0-9.]+s)"`)
+
+type RateLimitReporter func(provider string, wait time.Duration)
+
+type rateLimitReporterKey struct{}
+
+func WithRateLimitReporter(ctx context.Context, reporter RateLimitReporter) context.Context {
+	return context.WithValue(ctx, rateLimitReporterKey{}, reporter)
+}
+
+func reportRateLimit(ctx context.Context, provider string, wait time.Duration) {
+	if reporter, ok := ctx.Value(rateLimitReporterKey{}).(RateLimitReporter); ok && reporter != nil {
+		reporter(provider, wait)
+	}
+}
+
+func RetryAfter(err error) (time.Duration, bool) {
+	var statusErr statusError
+	if !errors.As(err, &statusErr) || statusErr.RetryAfter <= 0 {
+		return 0, false
+	}
+	return statusErr.RetryAfter, true
+}
+
+func rateLimitWait(ctx context.Context, err error) (time.Duration, bool) {
+	wait, ok := RetryAfter(err)
+	if !ok {
+		return 0, false
+	}
+	if deadline, hasDeadline := ctx.Deadline(); hasDeadline && wait >= time.Until(deadline) {
+		return 0, false
+	}
+	return wait, true
+}
+
+func sleepContext(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func parseRetryAfter(header http.Header, body string, now time.Time) time.Duration {
+	if value := strings.TrimSpace(header.Get("Retry-After")); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return time.Duration(max(seconds, 0)) * time.Second
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return max(at.Sub(now), 0)
+		}
+	}
+	wait := max(
+		parseRateLimitReset(header, "requests", now),
+		parseRateLimitReset(header, "tokens", now),
+		parseResetValue(header.Get("X-Ratelimit-Reset"), now),
+	)
+	if wait > 0 {
+		return wait
+	}
+	if match := retryDelayPattern.FindStringSubmatch(body); match != nil {
+		if delay, err := time.ParseDuration(match[1]); err == nil {
+			return delay
+		}
+	}
+	return 0
+}
+
+func parseRateLimitReset(header http.Header, kind string, now time.Time) time.Duration {
+	if remaining := header.Get("X-Ratelimit-Remaining-" + kind); remaining != "" && remaining != "0" {
+		return 0
+	}
+	return parseResetValue(header.Get("X-Ratelimit-Reset-"+kind), now)
+}
+
+func parseResetValue(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return max(duration, 0)
+	}
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	if seconds > float64(now.Unix()/2) {
+		return max(time.Unix(int64(seconds), 0).Sub(now), 0)
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,123 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name    string
+		headers map[string]string
+		body    string
+		want    time.Duration
+	}{
+		{"none", nil, "", 0},
+		{"retry-after seconds", map[string]string{"Retry-After": "12"}, "", 12 * time.Second},
+		{"retry-after date", map[string]string{"Retry-After": now.Add(30 * time.Second).Format(http.TimeFormat)}, "", 30 * time.Second},
+		{"retry-after past date", map[string]string{"Retry-After": now.Add(-time.Minute).Format(http.TimeFormat)}, "", 0},
+		{"openai reset duration", map[string]string{
+			"X-Ratelimit-Remaining-Requests": "0",
+			"X-Ratelimit-Reset-Requests":     "6m0s",
+		}, "", 6 * time.Minute},
+		{"reset ignored while requests remain", map[string]string{
+			"X-Ratelimit-Remaining-Requests": "42",
+			"X-Ratelimit-Reset-Requests":     "6m0s",
+			"X-Ratelimit-Remaining-Tokens":   "0",
+			"X-Ratelimit-Reset-Tokens":       "850ms",
+		}, "", 850 * time.Millisecond},
+		{"reset seconds", map[string]string{"X-Ratelimit-Reset": "7"}, "", 7 * time.Second},
+		{"reset unix timestamp", map[string]string{"X-Ratelimit-Reset": "1767323105"}, "", 60 * time.Second},
+		{"retry-after wins", map[string]string{"Retry-After": "3", "X-Ratelimit-Reset": "60"}, "", 3 * time.Second},
+		{"gemini retry delay", nil, `[{"error":{"details":[{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay": "12s"}]}}]`, 12 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for key, value := range tt.headers {
+				header.Set(key, value)
+			}
+			assert.Equal(t, tt.want, parseRetryAfter(header, tt.body, now))
+		})
+	}
+}
+
+func TestStartHTTPStreamKeepsRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "12")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := newOpenAICompatibleClient(server.URL, "", "model", nil).GenerateCommitMessageStream(context.Background(), "", "")
+
+	wait, ok := RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, 12*time.Second, wait)
+	assert.ErrorContains(t, err, "HTTP 429 (retry after 12s)")
+}
+
+func TestFallbackLinkWaitsForRateLimitWithinBudget(t *testing.T) {
+	calls := 0
+	client := funcClient(func(ctx context.Context, systemPrompt, userContent string) (Stream, error) {
+		calls++
+		if calls == 1 {
+			return nil, statusError{StatusCode: http.StatusTooManyRequests, RetryAfter: 10 * time.Millisecond}
+		}
+		return stubStream{chunks: []string{"fix: waited"}}, nil
+	})
+	var waits []time.Duration
+	ctx := WithRateLimitReporter(context.Background(), func(provider string, wait time.Duration) {
+		assert.Equal(t, "gemini", provider)
+		waits = append(waits, wait)
+	})
+
+	stream, err := (&fallbackClient{links: []fallbackLink{stubLink("gemini", client)}}).GenerateCommitMessageStream(ctx, "", "")
+	require.NoError(t, err)
+	message, err := stream.Collect(func(string) {})
+
+	require.NoError(t, err)
+	assert.Equal(t, "fix: waited", message)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, []time.Duration{10 * time.Millisecond}, waits)
+}
+
+func TestFallbackLinkFailsOverWhenRateLimitExceedsBudget(t *testing.T) {
+	limited := &stubClient{startErr: statusError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute}}
+	client := &fallbackClient{links: []fallbackLink{
+		stubLink("gemini", limited),
+		stubLink("ollama", &stubClient{stream: stubStream{chunks: []string{"fix: backup"}}}),
+	}}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var events []failoverEvent
+	ctx = WithFailoverReporter(ctx, func(failedProvider string, err error, nextProvider string) {
+		events = append(events, failoverEvent{failed: failedProvider, next: nextProvider})
+	})
+	ctx = WithRateLimitReporter(ctx, func(string, time.Duration) { t.Fatal("should not wait past the budget") })
+
+	stream, err := client.GenerateCommitMessageStream(ctx, "", "")
+	require.NoError(t, err)
+	message, err := stream.Collect(func(string) {})
+
+	require.NoError(t, err)
+	assert.Equal(t, "fix: backup", message)
+	assert.Equal(t, 1, limited.calls)
+	assert.Equal(t, []failoverEvent{{"gemini", "ollama"}}, events)
+}
+
+func TestRetryAfterIgnoresOtherErrors(t *testing.T) {
+	_, ok := RetryAfter(errors.New("plain"))
+	assert.False(t, ok)
+	_, ok = RetryAfter(statusError{StatusCode: http.StatusTooManyRequests})
+	assert.False(t, ok)
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Mayurifag/yawn/internal/config"
+	"github.com/Mayurifag/yawn/internal/git"
+	"github.com/Mayurifag/yawn/internal/ui"
+)
+
+func (a *App) RunAmend(ctx context.Context) error {
+	if err := a.ensureSSHRemote(); err != nil {
+		return err
+	}
+	if err := a.ensureAPIKey(); err != nil {
+		return err
+	}
+	base, err := a.GitClient.GetAmendBase()
+	if err != nil {
+		return err
+	}
+	if err := a.stageForAmend(); err != nil {
+		return err
+	}
+	pushed, err := a.GitClient.IsPushed("HEAD")
+	if err != nil {
+		return err
+	}
+
+	before := a.headHash()
+	if err := a.amendFromBase(ctx, base); err != nil {
+		return err
+	}
+	a.record(git.Operation{Kind: git.OpAmend, Before: before, After: a.headHash()})
+	ui.PrintSuccess("Successfully amended commit.")
+
+	if pushed {
+		return a.handleSquashPush()
+	}
+	return a.handlePushOperation()
+}
+
+func (a *App) stageForAmend() error {
+	hasUnstaged, err := a.GitClient.HasUnstagedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check for unstaged changes: %w", err)
+	}
+	switch {
+	case !hasUnstaged:
+		return nil
+	case a.Config.AutoStage:
+		ui.PrintInfo(fmt.Sprintf("Auto-staging changes (enabled via %s)...", a.Config.GetConfigSource("AutoStage")))
+		return a.stageAll()
+	case a.Config.GetStageMode() == config.StageModePick:
+		return a.pickAndStage(true)
+	}
+	if status, err := a.GitClient.GetStatusShort(); err == nil && status != "" {
+		ui.PrintDirtyChanges(status)
+	}
+	if !ui.AskYesNo("Include these changes in the amended commit?", false) {
+		return nil
+	}
+	return a.stageAll()
+}
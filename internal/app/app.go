@@ -55,7 +55,7 @@ func containsAny(s string, subs ...string) bool {
 }
 
 func (a *App) ensureAPIKey() error {
-	if !config.ProviderRequiresAPIKey(a.Config.GetMainProvider()) {
+	if !config.ProviderRequiresAPIKey(a.Config.GetProviderType(a.Config.GetMainProvider())) {
 		return nil
 	}
 	if a.Config.GetAPIKey() == "" {
@@ -101,6 +101,10 @@ func (a *App) ensureStagedChanges() error {
 		return fmt.Errorf("you have no changes to commit")
 	}
 
+	if hasUnstaged && a.Config.GetStageMode() == config.StageModePick {
+		return a.pickAndStage(hasStaged)
+	}
+
 	shouldStage := false
 	if hasUnstaged {
 		if a.Config.AutoStage {
@@ -115,12 +119,57 @@ func (a *App) ensureStagedChanges() error {
 	}
 
 	if shouldStage {
-		if err := a.GitClient.StageChanges(); err != nil {
-			return fmt.Errorf("failed to stage changes: %w", err)
+		return a.stageAll()
+	}
+
+	return nil
+}
+
+func (a *App) stageAll() error {
+	if err := a.guardStaging(); err != nil {
+		return err
+	}
+	if err := a.GitClient.StageChanges(); err != nil {
+		return fmt.Errorf("failed to stage changes: %w", err)
+	}
+	ui.PrintSuccess("Successfully staged changes.")
+	return nil
+}
+
+func (a *App) pickAndStage(hasStaged bool) error {
+	files, err := a.GitClient.ListUnstagedFiles()
+	if err != nil {
+		return err
+	}
+	labels := make([]string, len(files))
+	for i, f := range files {
+		stat := fmt.Sprintf("+%d -%d", f.Additions, f.Deletions)
+		if f.Binary {
+			stat = "binary"
 		}
-		ui.PrintSuccess("Successfully staged changes.")
+		labels[i] = fmt.Sprintf("%-12s %s (%s)", f.StatusLabel(), f.Path, stat)
 	}
 
+	picked := ui.PickFiles(labels)
+	paths := make([]string, len(picked))
+	for i, idx := range picked {
+		paths[i] = files[idx].Path
+	}
+	paths, err = a.guardPicked(paths)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		if hasStaged {
+			ui.PrintInfo("No files picked, committing what is already staged.")
+			return nil
+		}
+		return fmt.Errorf("no files picked to stage")
+	}
+	if err := a.GitClient.StagePaths(paths); err != nil {
+		return err
+	}
+	ui.PrintSuccess(fmt.Sprintf("Staged %d file(s).", len(paths)))
 	return nil
 }
 
@@ -129,9 +178,12 @@ func (a *App) generateAndCommitChanges(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to get staged changes: %w", err)
 	}
-	if diff == "" {
+	if diff.Text == "" {
 		return fmt.Errorf("no staged changes to commit")
 	}
+	if err := a.checkSecrets(diff.Secrets); err != nil {
+		return err
+	}
 
 	aiClient, err := ai.NewClient(a.Config)
 	if err != nil {
@@ -139,9 +191,9 @@ func (a *App) generateAndCommitChanges(ctx context.Context) error {
 	}
 
 	branchName, additions, deletions := a.gatherCommitInfo()
-	ui.PrintPreGenerationInfo(branchName, additions, deletions, a.Config.GetModelLabel())
+	ui.PrintPreGenerationInfo(branchName, additions, deletions, a.Config.GetModelLabel(), diff.Context)
 
-	message, err := a.generateCommitMessageAndStream(ctx, aiClient, a.Config.Prompt, diff)
+	message, err := a.generateFromDiff(ctx, aiClient, diff.Text, a.GitClient.GetDiffChunks)
 	if err != nil {
 		return err
 	}
@@ -190,9 +242,11 @@ func (a *App) Run(ctx context.Context) error {
 		return err
 	}
 
+	before := a.headHash()
 	if err := a.generateAndCommitChanges(ctx); err != nil {
 		return err
 	}
+	a.record(git.Operation{Kind: git.OpCommit, Before: before, After: a.headHash()})
 
 	return a.handlePushOperation()
 }
@@ -1,10 +1,14 @@
 package app
 
 import (
+	"errors"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/Mayurifag/yawn/internal/config"
 	"github.com/Mayurifag/yawn/internal/git"
+	"github.com/Mayurifag/yawn/internal/ui"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -61,3 +65,131 @@ func TestEnsureStagedChanges(t *testing.T) {
 		})
 	}
 }
+
+func TestEnsureStagedChanges_PickModeUsesPicker(t *testing.T) {
+	stagedAll := false
+	mockGit := &git.MockGitClient{
+		MockHasStagedChanges:   func() (bool, error) { return false, nil },
+		MockHasUnstagedChanges: func() (bool, error) { return true, nil },
+		MockListUnstagedFiles:  func() ([]git.ChangedFile, error) { return nil, errors.New("list failed") },
+		MockStageChanges: func() error {
+			stagedAll = true
+			return nil
+		},
+	}
+	a := &App{Config: config.Config{AutoStage: true, StageMode: config.StageModePick}, GitClient: mockGit}
+
+	assert.ErrorContains(t, a.ensureStagedChanges(), "list failed")
+	assert.False(t, stagedAll)
+}
+
+func TestEnsureStagedChanges_GuardRunsBeforeStaging(t *testing.T) {
+	staged := false
+	mockGit := &git.MockGitClient{
+		MockHasStagedChanges:      func() (bool, error) { return false, nil },
+		MockHasUnstagedChanges:    func() (bool, error) { return true, nil },
+		MockFindStagingViolations: func() ([]git.StagingViolation, error) { return nil, errors.New("ls-files failed") },
+		MockStageChanges: func() error {
+			staged = true
+			return nil
+		},
+	}
+	a := &App{Config: config.Config{AutoStage: true}, GitClient: mockGit}
+
+	assert.ErrorContains(t, a.ensureStagedChanges(), "failed to check files before staging")
+	assert.False(t, staged)
+
+	mockGit.MockFindStagingViolations = nil
+	require.NoError(t, a.ensureStagedChanges())
+	assert.True(t, staged)
+}
+
+func TestPickAndStageGuardsOnlyPickedFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		staged  []string
+		ignored []string
+		err     string
+	}{
+		{"unrelated flagged file is not checked", "2\n\n", []string{"main.go"}, nil, ""},
+		{"picked flagged file is refused", "1 2\n\n\n", nil, nil, "refusing to stage 1 flagged file(s)"},
+		{"ignored flagged file is dropped", "a\n\ng", []string{"main.go"}, []string{".env"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ui.SetInput(strings.NewReader(tt.input))
+			t.Cleanup(func() { ui.SetInput(os.Stdin) })
+			var staged, ignored []string
+			mockGit := &git.MockGitClient{
+				MockListUnstagedFiles: func() ([]git.ChangedFile, error) {
+					return []git.ChangedFile{{Path: ".env", Status: "?"}, {Path: "main.go", Status: "M"}}, nil
+				},
+				MockFindStagingViolations: func() ([]git.StagingViolation, error) {
+					return []git.StagingViolation{{Path: ".env", Reason: "credential file"}}, nil
+				},
+				MockIgnorePaths: func(paths []string, target string) error {
+					ignored = paths
+					return nil
+				},
+				MockStagePaths: func(paths []string) error {
+					staged = paths
+					return nil
+				},
+			}
+			a := &App{Config: config.Config{StageGuard: config.StageGuard{Action: config.GuardActionRefuse}}, GitClient: mockGit}
+
+			err := a.pickAndStage(false)
+
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.staged, staged)
+			assert.Equal(t, tt.ignored, ignored)
+		})
+	}
+}
+
+func TestStageForAmend(t *testing.T) {
+	tests := []struct {
+		name        string
+		autoStage   bool
+		hasUnstaged bool
+		expectStage bool
+	}{
+		{"auto_stage adds unstaged changes", true, true, true},
+		{"no unstaged changes amends the index as is", true, false, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			staged := false
+			mockGit := &git.MockGitClient{
+				MockHasUnstagedChanges: func() (bool, error) { return tc.hasUnstaged, nil },
+				MockStageChanges: func() error {
+					staged = true
+					return nil
+				},
+			}
+			a := &App{Config: config.Config{AutoStage: tc.autoStage}, GitClient: mockGit}
+
+			require.NoError(t, a.stageForAmend())
+			assert.Equal(t, tc.expectStage, staged)
+		})
+	}
+}
+
+func TestCheckSecretsBlocksAddedSecrets(t *testing.T) {
+	added := []git.SecretFinding{{Path: "config.go", Line: 4, Rule: "github_token", Added: true}}
+	removed := []git.SecretFinding{{Path: "config.go", Line: 4, Rule: "github_token"}}
+
+	a := &App{Config: config.Config{SecretScan: config.SecretScan{Enabled: true, BlockAdded: true}}}
+	assert.ErrorContains(t, a.checkSecrets(added), "refusing to commit")
+	assert.NoError(t, a.checkSecrets(removed))
+	assert.NoError(t, a.checkSecrets(nil))
+
+	a.Config.SecretScan.BlockAdded = false
+	assert.NoError(t, a.checkSecrets(added))
+}
@@ -33,5 +33,5 @@ func (a *App) RunForcePush() error {
 		ui.PrintInfo(fmt.Sprintf("Auto force-pushing (enabled via %s)...", a.Config.GetConfigSource("AutoPush")))
 	}
 
-	return a.doPush(pushCmd, "Force-pushing...", "Successfully force-pushed.")
+	return a.forcePush(pushCmd, "Force-pushing...", "Successfully force-pushed.")
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Mayurifag/yawn/internal/ai"
@@ -18,9 +19,35 @@ func (a *App) doGenerateStream(ctx context.Context, aiClient ai.Client, systemPr
 	ctxTimeout, cancel := context.WithTimeout(ctx, a.Config.GetRequestTimeout())
 	defer cancel()
 
-	spinner := ui.StartSpinner("Generating commit message...")
+	const spinnerText = "Generating commit message..."
+	spinner := ui.StartSpinner(spinnerText)
+	starting := true
+	printer := &streamPrinter{}
+	pauseSpinner := func(report func()) {
+		if starting {
+			ui.StopSpinner(spinner)
+		}
+		report()
+		if starting {
+			spinner = ui.StartSpinner(spinnerText)
+		}
+	}
+	ctxTimeout = ai.WithFailoverReporter(ctxTimeout, func(failedProvider string, err error, nextProvider string) {
+		pauseSpinner(func() {
+			if printer.discard() {
+				ui.PrintInfo("Discarded partial commit message.")
+			}
+			a.reportFailover(failedProvider, err, nextProvider)
+		})
+	})
+	ctxTimeout = ai.WithRateLimitReporter(ctxTimeout, func(provider string, wait time.Duration) {
+		pauseSpinner(func() {
+			ui.PrintInfo(fmt.Sprintf("Rate limited by %s, retrying in %s", provider, wait.Round(100*time.Millisecond)))
+		})
+	})
 	stream, err := aiClient.GenerateCommitMessageStream(ctxTimeout, systemPrompt, userContent)
 	ui.StopSpinner(spinner)
+	starting = false
 
 	if err != nil {
 		if errors.Is(ctxTimeout.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) {
@@ -30,8 +57,13 @@ func (a *App) doGenerateStream(ctx context.Context, aiClient ai.Client, systemPr
 	}
 
 	ui.PrintInfo("Generated commit message:")
-	message, err := stream.Collect(func(chunk string) { fmt.Print(chunk) })
-	fmt.Println()
+	message, err := stream.Collect(printer.print)
+	if err != nil && printer.discard() {
+		ui.ClearLine()
+		ui.PrintInfo(fmt.Sprintf("Stream interrupted, discarded partial commit message: %v", err))
+	} else {
+		fmt.Println()
+	}
 	if err != nil {
 		if errors.Is(ctxTimeout.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) {
 			return "", fmt.Errorf("commit message generation timed out after %s: %w", a.Config.GetRequestTimeout(), errRequestTimeout)
@@ -44,6 +76,33 @@ func (a *App) doGenerateStream(ctx context.Context, aiClient ai.Client, systemPr
 	return message, nil
 }
 
+type streamPrinter struct {
+	printed strings.Builder
+}
+
+func (p *streamPrinter) print(chunk string) {
+	fmt.Print(chunk)
+	p.printed.WriteString(chunk)
+}
+
+func (p *streamPrinter) discard() bool {
+	if p.printed.Len() == 0 {
+		return false
+	}
+	ui.ClearStreamedText(p.printed.String())
+	p.printed.Reset()
+	return true
+}
+
+func (a *App) reportFailover(failedProvider string, err error, nextProvider string) {
+	if errors.Is(err, ai.ErrHedgeWindowElapsed) {
+		ui.PrintInfo(fmt.Sprintf("No output from %s after %s, racing %s...", failedProvider, a.Config.GetHedgeAfter(), nextProvider))
+		return
+	}
+	ui.PrintError(fmt.Sprintf("%s failed: %v", failedProvider, err))
+	ui.PrintInfo(fmt.Sprintf("Trying %s...", nextProvider))
+}
+
 func (a *App) generateCommitMessageAndStream(ctx context.Context, aiClient ai.Client, systemPrompt, userContent string) (string, error) {
 	var lastErr error
 	for attempt := range maxCommitGenRetries {
@@ -52,7 +111,8 @@ func (a *App) generateCommitMessageAndStream(ctx context.Context, aiClient ai.Cl
 			return msg, nil
 		}
 		lastErr = err
-		isRetryable := ai.IsTransientError(err) || errors.Is(err, errRequestTimeout)
+		_, rateLimited := ai.RetryAfter(err)
+		isRetryable := (ai.IsTransientError(err) && !rateLimited) || errors.Is(err, errRequestTimeout)
 		if !isRetryable || attempt == maxCommitGenRetries-1 || ctx.Err() != nil {
 			return "", err
 		}
@@ -3,10 +3,13 @@ package app
 import (
 	"context"
 	"errors"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/Mayurifag/yawn/internal/ai"
 	"github.com/Mayurifag/yawn/internal/config"
+	"github.com/Mayurifag/yawn/internal/git"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -53,3 +56,55 @@ func TestGenerateCommitMessageRetriesStreamDeadline(t *testing.T) {
 	assert.Equal(t, "fix: retry deadline", message)
 	assert.Equal(t, 2, client.calls)
 }
+
+type summarizingAIClient struct {
+	mu       sync.Mutex
+	prompts  []string
+	contents []string
+}
+
+func (c *summarizingAIClient) GenerateCommitMessageStream(ctx context.Context, systemPrompt, userContent string) (ai.Stream, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prompts = append(c.prompts, systemPrompt)
+	c.contents = append(c.contents, userContent)
+	if systemPrompt == config.SummaryPrompt {
+		return fakeAIStream{message: "- summary of " + userContent}, nil
+	}
+	return fakeAIStream{message: "feat: large change"}, nil
+}
+
+func TestGenerateFromDiffSummarizesOversizedDiff(t *testing.T) {
+	client := &summarizingAIClient{}
+	a := &App{Config: config.Config{RequestTimeoutSeconds: 30, SummarizeLargeDiffs: true, SummaryConcurrency: 2, SummaryChunkBytes: 100}}
+	chunker := func(chunkBytes int) (git.DiffChunks, error) {
+		assert.Equal(t, 100, chunkBytes)
+		return git.DiffChunks{Chunks: []string{"a.go" + strings.Repeat(" ", git.MaxDiffBytes), "b.go", "c.go"}, Redacted: "### Files redacted from diff (summary only):\n- go.sum: lockfile, +1 -1\n"}, nil
+	}
+
+	message, err := a.generateFromDiff(context.Background(), client, "truncated diff", chunker)
+
+	require.NoError(t, err)
+	assert.Equal(t, "feat: large change", message)
+	require.Len(t, client.contents, 4)
+	final := client.contents[3]
+	assert.Equal(t, "", client.prompts[3])
+	assert.Contains(t, final, "#### Part 1/3\n- summary of a.go")
+	assert.Contains(t, final, "#### Part 2/3\n- summary of b.go")
+	assert.Contains(t, final, "#### Part 3/3\n- summary of c.go")
+	assert.Contains(t, final, "go.sum: lockfile")
+}
+
+func TestGenerateFromDiffSendsSmallDiffDirectly(t *testing.T) {
+	client := &summarizingAIClient{}
+	a := &App{Config: config.Config{RequestTimeoutSeconds: 30, SummarizeLargeDiffs: true}}
+	chunker := func(int) (git.DiffChunks, error) {
+		return git.DiffChunks{Chunks: []string{"diff --git a/a.go b/a.go", "diff --git a/b.go b/b.go"}}, nil
+	}
+
+	message, err := a.generateFromDiff(context.Background(), client, "diff --git a/a.go b/a.go", chunker)
+
+	require.NoError(t, err)
+	assert.Equal(t, "feat: large change", message)
+	assert.Equal(t, []string{"diff --git a/a.go b/a.go"}, client.contents)
+}
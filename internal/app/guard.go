@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Mayurifag/yawn/internal/config"
+	"github.com/Mayurifag/yawn/internal/git"
+	"github.com/Mayurifag/yawn/internal/ui"
+)
+
+func (a *App) guardStaging() error {
+	violations, err := a.GitClient.FindStagingViolations()
+	if err != nil {
+		return fmt.Errorf("failed to check files before staging: %w", err)
+	}
+	_, err = a.resolveViolations(violations)
+	return err
+}
+
+func (a *App) guardPicked(paths []string) ([]string, error) {
+	all, err := a.GitClient.FindStagingViolations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check files before staging: %w", err)
+	}
+	var violations []git.StagingViolation
+	for _, v := range all {
+		if slices.ContainsFunc(paths, v.Covers) {
+			violations = append(violations, v)
+		}
+	}
+	ignored, err := a.resolveViolations(violations)
+	if err != nil || !ignored {
+		return paths, err
+	}
+	return slices.DeleteFunc(paths, func(p string) bool {
+		return slices.ContainsFunc(violations, func(v git.StagingViolation) bool { return v.Covers(p) })
+	}), nil
+}
+
+func (a *App) resolveViolations(violations []git.StagingViolation) (bool, error) {
+	if len(violations) == 0 {
+		return false, nil
+	}
+	lines := make([]string, len(violations))
+	paths := make([]string, len(violations))
+	for i, v := range violations {
+		lines[i] = v.String()
+		paths[i] = v.Path
+	}
+	ui.PrintStagingViolations(lines)
+
+	allowStage := a.Config.StageGuard.GetAction() != config.GuardActionRefuse
+	switch ui.AskStagingGuardAction(allowStage) {
+	case "g":
+		return true, a.ignoreViolations(paths, git.IgnoreGitignore, ".gitignore")
+	case "e":
+		return true, a.ignoreViolations(paths, git.IgnoreExclude, ".git/info/exclude")
+	case "s":
+		if allowStage {
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("refusing to stage %d flagged file(s) (stage_guard enabled via %s)", len(violations), a.Config.GetConfigSource("StageGuard"))
+}
+
+func (a *App) ignoreViolations(paths []string, target, name string) error {
+	if err := a.GitClient.IgnorePaths(paths, target); err != nil {
+		return err
+	}
+	ui.PrintSuccess(fmt.Sprintf("Added %d path(s) to %s.", len(paths), name))
+	return nil
+}
@@ -177,6 +177,9 @@ func (a *App) handleOriginDivergence(localOnly, remoteOnly []string) error {
 	} else {
 		ui.PrintInfo(fmt.Sprintf("Auto-pushing %d commit(s) (enabled via %s)...", len(localOnly), a.Config.GetConfigSource("AutoPush")))
 	}
+	if needsForce {
+		return a.forcePush(pushCmd, "Pushing...", "Successfully pushed.")
+	}
 	return a.doPush(pushCmd, "Pushing...", "Successfully pushed.")
 }
 
@@ -188,7 +191,7 @@ func (a *App) handleNonFastForwardPush(pushCmd, spinnerText, successMsg string)
 	if !ui.AskYesNo(fmt.Sprintf("Overwrite remote? (using: %s)", forcePushCmd), false) {
 		return nil
 	}
-	return a.doPush(forcePushCmd, spinnerText, successMsg)
+	return a.forcePush(forcePushCmd, spinnerText, successMsg)
 }
 
 func (a *App) handleSquashPush() error {
@@ -214,5 +217,5 @@ func (a *App) handleSquashPush() error {
 		ui.PrintInfo(fmt.Sprintf("Auto force-pushing (enabled via %s)...", a.Config.GetConfigSource("SquashAutoPush")))
 	}
 
-	return a.doPush(pushCmd, "Force-pushing...", "Successfully force-pushed.")
+	return a.forcePush(pushCmd, "Force-pushing...", "Successfully force-pushed.")
 }
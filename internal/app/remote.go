@@ -42,6 +42,7 @@ func (a *App) ensureSSHRemote() error {
 	if err := a.GitClient.SetRemoteURL("", sshURL); err != nil {
 		return fmt.Errorf("failed to switch remote to SSH: %w", err)
 	}
+	a.record(git.Operation{Kind: git.OpRemoteURL, Remote: "origin", Before: currentURL, After: sshURL})
 	ui.PrintSuccess(fmt.Sprintf("Remote 'origin' set to %s", sshURL))
 	return nil
 }
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Mayurifag/yawn/internal/ai"
+	"github.com/Mayurifag/yawn/internal/git"
+	"github.com/Mayurifag/yawn/internal/ui"
+)
+
+func (a *App) RunReword(ctx context.Context, revRange string) error {
+	if err := a.ensureSSHRemote(); err != nil {
+		return err
+	}
+	if err := a.ensureAPIKey(); err != nil {
+		return err
+	}
+	commits, err := a.GitClient.ListRewordCommits(revRange)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("reword: no commits in %s", revRange)
+	}
+
+	aiClient, err := ai.NewClient(a.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create AI client: %w", err)
+	}
+	messages, err := a.reviewRewords(ctx, aiClient, commits)
+	if err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		ui.PrintInfo("No commit messages changed.")
+		return nil
+	}
+
+	pushed, err := a.GitClient.IsPushed(firstReworded(commits, messages))
+	if err != nil {
+		return err
+	}
+	if err := a.GitClient.RewordCommits(commits, messages); err != nil {
+		return err
+	}
+	a.record(git.Operation{Kind: git.OpReword, Before: commits[len(commits)-1].Hash, After: a.headHash()})
+	ui.PrintSuccess(fmt.Sprintf("Reworded %d commit(s).", len(messages)))
+
+	if pushed {
+		return a.handleSquashPush()
+	}
+	return a.handlePushOperation()
+}
+
+func (a *App) reviewRewords(ctx context.Context, aiClient ai.Client, commits []git.RewordCommit) (map[string]string, error) {
+	messages := map[string]string{}
+	for i, commit := range commits {
+		subject, _, _ := strings.Cut(commit.Message, "\n")
+		ui.PrintInfo(fmt.Sprintf("Commit %d/%d %s %s", i+1, len(commits), shortHash(commit.Hash), subject))
+
+		diff, err := a.GitClient.GetCommitDiff(commit.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if diff.Text == "" {
+			ui.PrintInfo("No changes in this commit, keeping its message.")
+			continue
+		}
+		proposed, err := a.proposeReword(ctx, aiClient, commit.Hash, diff)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			ui.PrintError(fmt.Sprintf("Skipping %s, keeping its message: %v", shortHash(commit.Hash), err))
+			continue
+		}
+
+		message, stop, err := a.askReword(commit.Message, proposed)
+		if err != nil {
+			return nil, err
+		}
+		if message != "" && message != commit.Message {
+			messages[commit.Hash] = message
+		}
+		if stop {
+			break
+		}
+	}
+	return messages, nil
+}
+
+func (a *App) proposeReword(ctx context.Context, aiClient ai.Client, hash string, diff git.Diff) (string, error) {
+	if err := a.checkSecrets(diff.Secrets); err != nil {
+		return "", err
+	}
+	return a.generateFromDiff(ctx, aiClient, diff.Text, func(chunkBytes int) (git.DiffChunks, error) {
+		return a.GitClient.GetCommitDiffChunks(hash, chunkBytes)
+	})
+}
+
+func (a *App) askReword(current, proposed string) (string, bool, error) {
+	for {
+		ui.PrintMessageComparison(current, proposed)
+		switch ui.AskRewordAction() {
+		case "a":
+			return proposed, false, nil
+		case "e":
+			editor, err := a.GitClient.GetEditor()
+			if err != nil {
+				return "", false, err
+			}
+			edited, err := ui.EditText(editor, proposed+"\n")
+			if err != nil {
+				return "", false, err
+			}
+			proposed = strings.TrimSpace(edited)
+			if proposed == "" {
+				return "", false, nil
+			}
+		case "q":
+			return "", true, nil
+		default:
+			return "", false, nil
+		}
+	}
+}
+
+func firstReworded(commits []git.RewordCommit, messages map[string]string) string {
+	for _, commit := range commits {
+		if _, ok := messages[commit.Hash]; ok {
+			return commit.Hash
+		}
+	}
+	return "HEAD"
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Mayurifag/yawn/internal/ai"
+	"github.com/Mayurifag/yawn/internal/config"
+	"github.com/Mayurifag/yawn/internal/git"
+	"github.com/Mayurifag/yawn/internal/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviewRewordsKeepsEmptyCommits(t *testing.T) {
+	commits := []git.RewordCommit{{Hash: "aaaaaaaaaa", Message: "wip"}}
+	mockGit := &git.MockGitClient{
+		MockGetCommitDiff: func(string) (git.Diff, error) { return git.Diff{}, nil },
+	}
+	aiClient := &fakeAIClient{}
+	a := &App{GitClient: mockGit}
+
+	messages, err := a.reviewRewords(context.Background(), aiClient, commits)
+
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+	assert.Zero(t, aiClient.calls)
+}
+
+func TestReviewRewordsSkipsFailedCommits(t *testing.T) {
+	ui.SetInput(strings.NewReader("a\n"))
+	t.Cleanup(func() { ui.SetInput(os.Stdin) })
+	commits := []git.RewordCommit{
+		{Hash: "aaaaaaaaaa", Message: "wip"},
+		{Hash: "bbbbbbbbbb", Message: "wip 2"},
+		{Hash: "cccccccccc", Message: "wip 3"},
+	}
+	mockGit := &git.MockGitClient{
+		MockGetCommitDiff: func(hash string) (git.Diff, error) {
+			if hash == "aaaaaaaaaa" {
+				return git.Diff{Text: "+token", Secrets: []git.SecretFinding{{Path: "a.go", Line: 1, Rule: "github_token", Added: true}}}, nil
+			}
+			return git.Diff{Text: "+code"}, nil
+		},
+	}
+	aiClient := &fakeAIClient{streams: []ai.Stream{
+		fakeAIStream{err: errors.New("model refused")},
+		fakeAIStream{message: "feat: add code"},
+	}}
+	a := &App{Config: config.Config{RequestTimeoutSeconds: 30, SecretScan: config.SecretScan{Enabled: true, BlockAdded: true}}, GitClient: mockGit}
+
+	messages, err := a.reviewRewords(context.Background(), aiClient, commits)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cccccccccc": "feat: add code"}, messages)
+	assert.Equal(t, 2, aiClient.calls)
+}
+
+func TestFirstReworded(t *testing.T) {
+	commits := []git.RewordCommit{{Hash: "a"}, {Hash: "b"}, {Hash: "c"}}
+	assert.Equal(t, "b", firstReworded(commits, map[string]string{"c": "fix: c", "b": "feat: b"}))
+	assert.Equal(t, "HEAD", firstReworded(commits, nil))
+}
@@ -0,0 +1,20 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/Mayurifag/yawn/internal/git"
+	"github.com/Mayurifag/yawn/internal/ui"
+)
+
+func (a *App) checkSecrets(findings []git.SecretFinding) error {
+	lines := make([]string, len(findings))
+	for i, f := range findings {
+		lines[i] = f.String()
+	}
+	ui.PrintMaskedSecrets(lines)
+	if a.Config.SecretScan.BlockAdded && git.HasAddedSecrets(findings) {
+		return fmt.Errorf("refusing to commit: staged changes add secrets (block_added enabled via %s)", a.Config.GetConfigSource("SecretScan"))
+	}
+	return nil
+}
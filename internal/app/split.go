@@ -0,0 +1,312 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Mayurifag/yawn/internal/ai"
+	"github.com/Mayurifag/yawn/internal/config"
+	"github.com/Mayurifag/yawn/internal/git"
+	"github.com/Mayurifag/yawn/internal/ui"
+)
+
+const splitPlanHelp = `# One "commit <message>" line per commit, followed by indented paths.
+# Use path#N to commit only the N-th hunk of a file.
+# Lines starting with # are ignored. Files left out stay staged.
+`
+
+type splitCommit struct {
+	message string
+	entries []string
+}
+
+type splitGroup struct {
+	message    string
+	selections []git.SplitSelection
+}
+
+func (a *App) RunSplit(ctx context.Context) error {
+	if err := a.ensureSSHRemote(); err != nil {
+		return err
+	}
+	if err := a.ensureAPIKey(); err != nil {
+		return err
+	}
+	if err := a.ensureStagedChanges(); err != nil {
+		return err
+	}
+	diff, snapshot, files, err := a.splitInput()
+	if err != nil {
+		return err
+	}
+
+	aiClient, err := ai.NewClient(a.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create AI client: %w", err)
+	}
+	plan, err := a.proposeSplitPlan(ctx, aiClient, diff, files)
+	if err != nil {
+		return err
+	}
+	groups, err := a.reviewSplitPlan(plan, files)
+	if err != nil {
+		return err
+	}
+	if err := a.commitSplitPlan(snapshot, groups); err != nil {
+		return err
+	}
+	a.record(git.Operation{Kind: git.OpSplit, Before: snapshot.Head, After: a.headHash()})
+	return a.handlePushOperation()
+}
+
+func (a *App) splitInput() (string, git.IndexSnapshot, []git.SplitFile, error) {
+	diff, err := a.GitClient.GetDiff()
+	if err != nil {
+		return "", git.IndexSnapshot{}, nil, fmt.Errorf("failed to get staged changes: %w", err)
+	}
+	if diff.Text == "" {
+		return "", git.IndexSnapshot{}, nil, fmt.Errorf("no staged changes to split")
+	}
+	if err := a.checkSecrets(diff.Secrets); err != nil {
+		return "", git.IndexSnapshot{}, nil, err
+	}
+	snapshot, err := a.GitClient.SnapshotIndex()
+	if err != nil {
+		return "", git.IndexSnapshot{}, nil, err
+	}
+	files, err := a.GitClient.ListSplitFiles(snapshot)
+	if err != nil {
+		return "", git.IndexSnapshot{}, nil, err
+	}
+	if len(files) == 0 || (len(files) == 1 && files[0].Hunks < 2) {
+		return "", git.IndexSnapshot{}, nil, fmt.Errorf("split: a single change is staged, nothing to split")
+	}
+	splitDiff, err := a.GitClient.GetSplitDiff(snapshot)
+	if err != nil {
+		return "", git.IndexSnapshot{}, nil, err
+	}
+	return splitDiff, snapshot, files, nil
+}
+
+func (a *App) proposeSplitPlan(ctx context.Context, aiClient ai.Client, diff string, files []git.SplitFile) (string, error) {
+	var b strings.Builder
+	b.WriteString("## Staged files\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "- %s (%d hunks)\n", f.Path, f.Hunks)
+	}
+	b.WriteString("\n## Hunks\n")
+	b.WriteString(diff)
+
+	ui.PrintInfo("Planning commits...")
+	plan, err := a.generateCommitMessageAndStream(ctx, aiClient, config.SplitPrompt, b.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to generate split plan: %w", err)
+	}
+	plan = stripCodeFences(plan)
+	if plan == "" {
+		return "", fmt.Errorf("empty split plan received from AI provider")
+	}
+	return plan, nil
+}
+
+func stripCodeFences(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "```") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func (a *App) reviewSplitPlan(plan string, files []git.SplitFile) ([]splitGroup, error) {
+	for {
+		commits, err := parseSplitPlan(plan)
+		var groups []splitGroup
+		var unassigned []string
+		if err == nil {
+			groups, unassigned, err = resolveSplitPlan(commits, files)
+		}
+		if commits != nil {
+			ui.PrintSplitPlan(formatSplitPlan(commits))
+		} else {
+			ui.PrintSplitPlan(plan)
+		}
+		if err != nil {
+			ui.PrintError(fmt.Sprintf("Invalid plan: %v", err))
+		} else if len(unassigned) > 0 {
+			ui.PrintInfo(fmt.Sprintf("Not in the plan, will stay staged: %s", strings.Join(unassigned, ", ")))
+		}
+
+		switch ui.AskSplitPlanAction(err == nil) {
+		case "a":
+			if err == nil {
+				return groups, nil
+			}
+		case "e":
+			editor, editorErr := a.GitClient.GetEditor()
+			if editorErr != nil {
+				return nil, editorErr
+			}
+			edited, editErr := ui.EditText(editor, splitPlanHelp+plan+"\n")
+			if editErr != nil {
+				return nil, editErr
+			}
+			plan = edited
+		default:
+			return nil, fmt.Errorf("split: cancelled")
+		}
+	}
+}
+
+func parseSplitPlan(text string) ([]splitCommit, error) {
+	var commits []splitCommit
+	for n, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+		case strings.HasPrefix(line, "commit "):
+			commits = append(commits, splitCommit{message: strings.TrimSpace(strings.TrimPrefix(line, "commit "))})
+		case line != trimmed && len(commits) > 0:
+			last := &commits[len(commits)-1]
+			last.entries = append(last.entries, strings.TrimPrefix(trimmed, "- "))
+		default:
+			return commits, fmt.Errorf("line %d: expected \"commit <message>\" or an indented path, got %q", n+1, line)
+		}
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("plan has no commits")
+	}
+	return commits, nil
+}
+
+func formatSplitPlan(commits []splitCommit) string {
+	var b strings.Builder
+	for i, c := range commits {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "commit %s\n", c.message)
+		for _, entry := range c.entries {
+			fmt.Fprintf(&b, "  %s\n", entry)
+		}
+	}
+	return b.String()
+}
+
+type splitAssignments struct {
+	whole map[string]bool
+	hunks map[string]map[int]bool
+}
+
+func (s *splitAssignments) assign(entry, path string, hunk int) error {
+	if s.whole[path] || (hunk == 0 && len(s.hunks[path]) > 0) || s.hunks[path][hunk] {
+		return fmt.Errorf("%s is assigned more than once", entry)
+	}
+	if hunk == 0 {
+		s.whole[path] = true
+		return nil
+	}
+	if s.hunks[path] == nil {
+		s.hunks[path] = map[int]bool{}
+	}
+	s.hunks[path][hunk] = true
+	return nil
+}
+
+func (s *splitAssignments) unassigned(files []git.SplitFile) []string {
+	var paths []string
+	for _, f := range files {
+		switch assigned := len(s.hunks[f.Path]); {
+		case s.whole[f.Path]:
+		case assigned == 0:
+			paths = append(paths, f.Path)
+		case assigned < f.Hunks:
+			paths = append(paths, fmt.Sprintf("%s (%d of %d hunks)", f.Path, f.Hunks-assigned, f.Hunks))
+		}
+	}
+	return paths
+}
+
+func (g *splitGroup) add(f git.SplitFile, hunk int) {
+	i := slices.IndexFunc(g.selections, func(sel git.SplitSelection) bool { return sel.Path == f.Path })
+	if i < 0 {
+		i = len(g.selections)
+		g.selections = append(g.selections, git.SplitSelection{Path: f.Path, OldPath: f.OldPath})
+	}
+	if hunk > 0 {
+		g.selections[i].Hunks = append(g.selections[i].Hunks, hunk)
+		slices.Sort(g.selections[i].Hunks)
+	}
+}
+
+func resolveSplitPlan(commits []splitCommit, files []git.SplitFile) ([]splitGroup, []string, error) {
+	byPath := map[string]git.SplitFile{}
+	for _, f := range files {
+		byPath[f.Path] = f
+	}
+	assignments := &splitAssignments{whole: map[string]bool{}, hunks: map[string]map[int]bool{}}
+
+	groups := make([]splitGroup, len(commits))
+	for i, c := range commits {
+		if c.message == "" {
+			return nil, nil, fmt.Errorf("commit %d has no message", i+1)
+		}
+		if len(c.entries) == 0 {
+			return nil, nil, fmt.Errorf("commit %d (%s) has no files", i+1, c.message)
+		}
+		groups[i].message = c.message
+		for _, entry := range c.entries {
+			path, hunk, err := parseSplitEntry(entry, byPath)
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := assignments.assign(entry, path, hunk); err != nil {
+				return nil, nil, err
+			}
+			groups[i].add(byPath[path], hunk)
+		}
+	}
+	return groups, assignments.unassigned(files), nil
+}
+
+func parseSplitEntry(entry string, files map[string]git.SplitFile) (string, int, error) {
+	if _, ok := files[entry]; ok {
+		return entry, 0, nil
+	}
+	if i := strings.LastIndex(entry, "#"); i > 0 {
+		path := entry[:i]
+		hunk, err := strconv.Atoi(entry[i+1:])
+		if f, ok := files[path]; ok && err == nil {
+			if hunk < 1 || hunk > f.Hunks {
+				return "", 0, fmt.Errorf("%s: hunk %d out of range, the file has %d", path, hunk, f.Hunks)
+			}
+			return path, hunk, nil
+		}
+	}
+	return "", 0, fmt.Errorf("%s is not a staged file", entry)
+}
+
+func (a *App) commitSplitPlan(snapshot git.IndexSnapshot, groups []splitGroup) error {
+	for i, g := range groups {
+		if err := a.GitClient.CommitSelection(snapshot, g.selections, g.message); err != nil {
+			if restoreErr := a.restoreSnapshot(snapshot); restoreErr != nil {
+				return fmt.Errorf("split: commit %d/%d failed: %v; restoring HEAD %s and the index failed: %w", i+1, len(groups), err, snapshot.Head, restoreErr)
+			}
+			return fmt.Errorf("split: commit %d/%d failed, HEAD and the index were restored: %w", i+1, len(groups), err)
+		}
+		ui.PrintSuccess(fmt.Sprintf("Committed %d/%d: %s", i+1, len(groups), g.message))
+	}
+	return a.GitClient.ReadTree(snapshot.Tree)
+}
+
+func (a *App) restoreSnapshot(snapshot git.IndexSnapshot) error {
+	if err := a.GitClient.ResetSoft(snapshot.Head); err != nil {
+		return err
+	}
+	return a.GitClient.ReadTree(snapshot.Tree)
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Mayurifag/yawn/internal/ai"
+	"github.com/Mayurifag/yawn/internal/config"
+	"github.com/Mayurifag/yawn/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var splitTestFiles = []git.SplitFile{
+	{Path: "api/user.go", Hunks: 3},
+	{Path: "docs/api.md", Hunks: 1},
+	{Path: "new/name.go", OldPath: "old/name.go", Hunks: 1},
+	{Path: "README.md", Hunks: 1},
+}
+
+func TestParseAndResolveSplitPlan(t *testing.T) {
+	plan := `# comment
+commit feat(api): add user endpoint
+  api/user.go#3
+  - api/user.go#1
+  new/name.go
+
+commit docs: describe the endpoint
+  docs/api.md
+`
+	commits, err := parseSplitPlan(plan)
+	require.NoError(t, err)
+	assert.Equal(t, "commit feat(api): add user endpoint\n  api/user.go#3\n  api/user.go#1\n  new/name.go\n\ncommit docs: describe the endpoint\n  docs/api.md\n", formatSplitPlan(commits))
+
+	groups, unassigned, err := resolveSplitPlan(commits, splitTestFiles)
+	require.NoError(t, err)
+	assert.Equal(t, []splitGroup{
+		{message: "feat(api): add user endpoint", selections: []git.SplitSelection{
+			{Path: "api/user.go", Hunks: []int{1, 3}},
+			{Path: "new/name.go", OldPath: "old/name.go"},
+		}},
+		{message: "docs: describe the endpoint", selections: []git.SplitSelection{{Path: "docs/api.md"}}},
+	}, groups)
+	assert.Equal(t, []string{"api/user.go (1 of 3 hunks)", "README.md"}, unassigned)
+}
+
+func TestResolveSplitPlanRejectsInvalidPlans(t *testing.T) {
+	tests := []struct {
+		plan string
+		want string
+	}{
+		{"feat: no commit prefix\n  api/user.go", "line 1"},
+		{"", "plan has no commits"},
+		{"commit feat: empty", "has no files"},
+		{"commit feat: a\n  missing.go", "missing.go is not a staged file"},
+		{"commit feat: a\n  api/user.go#4", "hunk 4 out of range"},
+		{"commit feat: a\n  api/user.go\ncommit fix: b\n  api/user.go#2", "api/user.go#2 is assigned more than once"},
+		{"commit feat: a\n  docs/api.md\n  docs/api.md", "docs/api.md is assigned more than once"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			commits, err := parseSplitPlan(tt.plan)
+			if err == nil {
+				_, _, err = resolveSplitPlan(commits, splitTestFiles)
+			}
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestCommitSplitPlanRestoresOnFailure(t *testing.T) {
+	snapshot := git.IndexSnapshot{Head: "abc123", Tree: "tree456"}
+	var committed []string
+	var resetTo, readTree string
+	mockGit := &git.MockGitClient{
+		MockCommitSelection: func(s git.IndexSnapshot, selections []git.SplitSelection, message string) error {
+			if len(committed) == 1 {
+				return errors.New("hook rejected commit")
+			}
+			committed = append(committed, message)
+			return nil
+		},
+		MockResetSoft: func(commit string) error {
+			resetTo = commit
+			return nil
+		},
+		MockReadTree: func(tree string) error {
+			readTree = tree
+			return nil
+		},
+	}
+	a := &App{GitClient: mockGit}
+
+	err := a.commitSplitPlan(snapshot, []splitGroup{{message: "feat: a"}, {message: "fix: b"}})
+
+	assert.ErrorContains(t, err, "split: commit 2/2 failed, HEAD and the index were restored: hook rejected commit")
+	assert.Equal(t, []string{"feat: a"}, committed)
+	assert.Equal(t, "abc123", resetTo)
+	assert.Equal(t, "tree456", readTree)
+}
+
+func TestProposeSplitPlanStripsCodeFences(t *testing.T) {
+	client := &fakeAIClient{streams: []ai.Stream{
+		fakeAIStream{message: "```\ncommit feat: a\n  api/user.go\n```\n"},
+	}}
+	a := &App{Config: config.Config{RequestTimeoutSeconds: 30}}
+
+	plan, err := a.proposeSplitPlan(context.Background(), client, "diff", splitTestFiles)
+
+	require.NoError(t, err)
+	assert.Equal(t, "commit feat: a\n  api/user.go", plan)
+}
+
+func TestProposeSplitPlanRetriesStreamDeadline(t *testing.T) {
+	client := &fakeAIClient{streams: []ai.Stream{
+		fakeAIStream{err: context.DeadlineExceeded},
+		fakeAIStream{message: "commit feat: a\n  api/user.go"},
+	}}
+	a := &App{Config: config.Config{RequestTimeoutSeconds: 30}}
+
+	plan, err := a.proposeSplitPlan(context.Background(), client, "diff", splitTestFiles)
+
+	require.NoError(t, err)
+	assert.Equal(t, "commit feat: a\n  api/user.go", plan)
+	assert.Equal(t, 2, client.calls)
+}
@@ -3,6 +3,9 @@ package app
 import (
 	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/Mayurifag/yawn/internal/ai"
 	"github.com/Mayurifag/yawn/internal/git"
@@ -102,16 +105,30 @@ func (a *App) handleSingleCommit(ctx context.Context, base string) error {
 		return err
 	}
 	ui.PrintInfo("Staging all changes and amending commit...")
-	if err := a.GitClient.StageChanges(); err != nil {
+	if err := a.stageAll(); err != nil {
 		return err
 	}
-
-	diff, err := a.GitClient.GetDiffCachedRange(base)
+	branch, err := a.GitClient.GetCurrentBranch()
 	if err != nil {
-		return fmt.Errorf("failed to get branch changes: %w", err)
+		return err
 	}
-	if diff == "" {
-		return fmt.Errorf("no branch changes to amend")
+	backup, err := a.GitClient.CreateBackupRef(branch)
+	if err != nil {
+		return err
+	}
+	before := a.headHash()
+	if err := a.amendFromBase(ctx, base); err != nil {
+		return err
+	}
+	a.record(git.Operation{Kind: git.OpAmend, Branch: branch, Before: before, After: a.headHash(), BackupRef: backup})
+	ui.PrintInfo(fmt.Sprintf("The original commit is kept at %s (restore with: git reset --soft %s).", backup, backup))
+	return a.handleSquashPush()
+}
+
+func (a *App) amendFromBase(ctx context.Context, base string) error {
+	diff, err := a.branchDiff(base)
+	if err != nil {
+		return err
 	}
 
 	aiClient, err := ai.NewClient(a.Config)
@@ -124,16 +141,26 @@ func (a *App) handleSingleCommit(ctx context.Context, base string) error {
 		branchName = "unknown"
 	}
 	additions, deletions, _ := a.GitClient.GetDiffNumStatCachedRange(base)
-	ui.PrintPreGenerationInfo(branchName, additions, deletions, a.Config.GetModelLabel())
+	ui.PrintPreGenerationInfo(branchName, additions, deletions, a.Config.GetModelLabel(), diff.Context)
 
-	message, err := a.generateCommitMessageAndStream(ctx, aiClient, a.Config.Prompt, diff)
+	message, err := a.generateFromDiff(ctx, aiClient, diff.Text, func(chunkBytes int) (git.DiffChunks, error) {
+		return a.GitClient.GetDiffChunksCachedRange(base, chunkBytes)
+	})
 	if err != nil {
 		return err
 	}
-	if err := a.GitClient.AmendCommit(message); err != nil {
-		return err
+	return a.GitClient.AmendCommit(message)
+}
+
+func (a *App) branchDiff(base string) (git.Diff, error) {
+	diff, err := a.GitClient.GetDiffCachedRange(base)
+	if err != nil {
+		return git.Diff{}, fmt.Errorf("failed to get branch changes: %w", err)
 	}
-	return a.handleSquashPush()
+	if diff.Text == "" {
+		return git.Diff{}, fmt.Errorf("no branch changes to amend")
+	}
+	return diff, a.checkSecrets(diff.Secrets)
 }
 
 func (a *App) handleMultiCommitSquash(ctx context.Context, base string, count int) (err error) {
@@ -150,19 +177,31 @@ func (a *App) handleMultiCommitSquash(ctx context.Context, base string, count in
 	}
 
 	if action == dirtyAdd {
-		ui.PrintInfo("Staging all changes...")
-		if err := a.GitClient.StageChanges(); err != nil {
+		if err := a.stageAll(); err != nil {
 			return err
 		}
 	}
 
+	branch, err := a.GitClient.GetCurrentBranch()
+	if err != nil {
+		return err
+	}
+	backup, err := a.GitClient.CreateBackupRef(branch)
+	if err != nil {
+		return err
+	}
+
+	var stashID string
 	if action == dirtyStash {
-		if err = a.GitClient.Stash(); err != nil {
+		if stashID, err = a.GitClient.Stash(); err != nil {
 			return err
 		}
+	}
+	if stashID != "" {
 		defer func() {
 			if popErr := a.GitClient.StashPop(); popErr != nil {
 				ui.PrintError(fmt.Sprintf("stash pop failed: %v", popErr))
+				ui.PrintRecoverySteps([]string{"git stash apply " + stashID})
 				if err == nil {
 					err = popErr
 				}
@@ -170,16 +209,42 @@ func (a *App) handleMultiCommitSquash(ctx context.Context, base string, count in
 		}()
 	}
 
-	if err := a.GitClient.ResetSoft(base); err != nil {
+	before := a.headHash()
+	if err := a.squashOnto(ctx, base, count, backup, stashID); err != nil {
 		return err
 	}
+	a.record(git.Operation{Kind: git.OpSquash, Branch: branch, Before: before, After: a.headHash(), BackupRef: backup, StashID: stashID})
+	ui.PrintInfo(fmt.Sprintf("Original commits are kept at %s (restore with: git reset --soft %s).", backup, backup))
+	return a.handleSquashPush()
+}
 
-	ui.PrintInfo(fmt.Sprintf("Squashing %d commits into 1...", count))
-	if err := a.generateAndCommitChanges(ctx); err != nil {
+func (a *App) squashOnto(ctx context.Context, base string, count int, backup, stashID string) (err error) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	defer func() {
+		if err != nil {
+			a.rollbackSquash(backup, stashID)
+		}
+	}()
+
+	if err := a.GitClient.ResetSoft(base); err != nil {
 		return err
 	}
+	ui.PrintInfo(fmt.Sprintf("Squashing %d commits into 1...", count))
+	return a.generateAndCommitChanges(ctx)
+}
 
-	return a.handleSquashPush()
+func (a *App) rollbackSquash(backup, stashID string) {
+	if err := a.GitClient.ResetSoft(backup); err != nil {
+		ui.PrintError(fmt.Sprintf("Rolling back the squash failed: %v", err))
+		steps := []string{"git reset --soft " + backup}
+		if stashID != "" {
+			steps = append(steps, "git stash apply "+stashID)
+		}
+		ui.PrintRecoverySteps(steps)
+		return
+	}
+	ui.PrintInfo(fmt.Sprintf("Squash failed, the branch was restored from %s.", backup))
 }
 
 func (a *App) RunSquash(ctx context.Context) error {
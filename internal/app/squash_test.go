@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Mayurifag/yawn/internal/config"
+	"github.com/Mayurifag/yawn/internal/git"
+	"github.com/Mayurifag/yawn/internal/ui"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSquashOntoRollsBackOnFailure(t *testing.T) {
+	var resets []string
+	mockGit := &git.MockGitClient{
+		MockResetSoft: func(commit string) error {
+			resets = append(resets, commit)
+			return nil
+		},
+		MockGetDiff: func() (git.Diff, error) { return git.Diff{}, errors.New("diff failed") },
+	}
+	a := &App{GitClient: mockGit}
+
+	err := a.squashOnto(context.Background(), "base", 3, "refs/yawn/backup/feature/20240101-000000", "")
+
+	assert.ErrorContains(t, err, "diff failed")
+	assert.Equal(t, []string{"base", "refs/yawn/backup/feature/20240101-000000"}, resets)
+}
+
+func TestSquashStagingRunsStageGuard(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		squash func(a *App) error
+	}{
+		{"single commit", func(a *App) error { return a.handleSingleCommit(context.Background(), "base") }},
+		{"multiple commits", func(a *App) error { return a.handleMultiCommitSquash(context.Background(), "base", 2) }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ui.SetInput(strings.NewReader("a\n\n"))
+			t.Cleanup(func() { ui.SetInput(os.Stdin) })
+			staged := false
+			mockGit := &git.MockGitClient{
+				MockHasAnyChanges: func() (bool, error) { return true, nil },
+				MockFindStagingViolations: func() ([]git.StagingViolation, error) {
+					return []git.StagingViolation{{Path: ".env", Reason: "secret file"}}, nil
+				},
+				MockStageChanges: func() error {
+					staged = true
+					return nil
+				},
+			}
+			a := &App{GitClient: mockGit, Config: config.Config{MainProvider: config.ProviderOpenCodeCLI}}
+
+			err := tt.squash(a)
+
+			assert.ErrorContains(t, err, "refusing to stage")
+			assert.False(t, staged)
+		})
+	}
+}
+
+func TestHandleSingleCommitBacksUpBeforeAmending(t *testing.T) {
+	ui.SetInput(strings.NewReader("a\n"))
+	t.Cleanup(func() { ui.SetInput(os.Stdin) })
+	var calls []string
+	mockGit := &git.MockGitClient{
+		MockHasAnyChanges:    func() (bool, error) { return true, nil },
+		MockGetCurrentBranch: func() (string, error) { return "feature", nil },
+		MockCreateBackupRef: func(branch string) (string, error) {
+			calls = append(calls, "backup "+branch)
+			return "refs/yawn/backup/feature/20240101-000000", nil
+		},
+		MockGetDiffCachedRange: func(base string) (git.Diff, error) {
+			calls = append(calls, "diff "+base)
+			return git.Diff{}, nil
+		},
+	}
+	a := &App{GitClient: mockGit, Config: config.Config{MainProvider: config.ProviderOpenCodeCLI}}
+
+	err := a.handleSingleCommit(context.Background(), "base")
+
+	assert.ErrorContains(t, err, "no branch changes to amend")
+	assert.Equal(t, []string{"backup feature", "diff base"}, calls)
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Mayurifag/yawn/internal/ai"
+	"github.com/Mayurifag/yawn/internal/config"
+	"github.com/Mayurifag/yawn/internal/git"
+	"github.com/Mayurifag/yawn/internal/ui"
+)
+
+type diffChunker func(chunkBytes int) (git.DiffChunks, error)
+
+func (a *App) generateFromDiff(ctx context.Context, aiClient ai.Client, diff string, chunker diffChunker) (string, error) {
+	diff, err := a.summarizeLargeDiff(ctx, aiClient, diff, chunker)
+	if err != nil {
+		return "", err
+	}
+	return a.generateCommitMessageAndStream(ctx, aiClient, a.Config.Prompt, diff)
+}
+
+func (a *App) summarizeLargeDiff(ctx context.Context, aiClient ai.Client, diff string, chunker diffChunker) (string, error) {
+	if !a.Config.SummarizeLargeDiffs {
+		return diff, nil
+	}
+	chunks, err := chunker(a.Config.GetSummaryChunkBytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to split diff into chunks: %w", err)
+	}
+	if len(chunks.Chunks) < 2 || chunks.Size() <= git.MaxDiffBytes {
+		return diff, nil
+	}
+	summaries, err := a.summarizeChunks(ctx, aiClient, chunks.Chunks)
+	if err != nil {
+		return "", err
+	}
+	return formatChunkSummaries(summaries, chunks.Redacted), nil
+}
+
+func (a *App) summarizeChunks(ctx context.Context, aiClient ai.Client, chunks []string) ([]string, error) {
+	spinner := ui.StartSpinner(fmt.Sprintf("Diff is too large, summarizing it in %d parts...", len(chunks)))
+	defer ui.StopSpinner(spinner)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	summaries := make([]string, len(chunks))
+	slots := make(chan struct{}, a.Config.GetSummaryConcurrency())
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for i, chunk := range chunks {
+		wg.Go(func() {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-slots }()
+			summary, err := a.summarizeChunk(ctx, aiClient, chunk)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil && !errors.Is(err, context.Canceled) {
+					firstErr = fmt.Errorf("failed to summarize diff part %d/%d: %w", i+1, len(chunks), err)
+				}
+				mu.Unlock()
+				cancel()
+				return
+			}
+			summaries[i] = summary
+		})
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+func (a *App) summarizeChunk(ctx context.Context, aiClient ai.Client, chunk string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.Config.GetRequestTimeout())
+	defer cancel()
+
+	stream, err := aiClient.GenerateCommitMessageStream(ctx, config.SummaryPrompt, chunk)
+	if err != nil {
+		return "", err
+	}
+	summary, err := stream.Collect(func(string) {})
+	if err != nil {
+		return "", err
+	}
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return "", fmt.Errorf("empty summary received from AI provider")
+	}
+	return summary, nil
+}
+
+func formatChunkSummaries(summaries []string, redacted string) string {
+	var b strings.Builder
+	b.WriteString("### Diff too large to include in full. Summaries of its parts:\n")
+	for i, summary := range summaries {
+		fmt.Fprintf(&b, "\n#### Part %d/%d\n%s\n", i+1, len(summaries), summary)
+	}
+	if redacted != "" {
+		b.WriteString("\n")
+		b.WriteString(redacted)
+	}
+	return b.String()
+}
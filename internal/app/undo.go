@@ -0,0 +1,147 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Mayurifag/yawn/internal/git"
+	"github.com/Mayurifag/yawn/internal/ui"
+)
+
+func (a *App) record(op git.Operation) {
+	if op.Branch == "" {
+		op.Branch, _ = a.GitClient.GetCurrentBranch()
+	}
+	if err := a.GitClient.RecordOperation(op); err != nil {
+		ui.PrintError(fmt.Sprintf("Warning: failed to record the %s for undo: %v", op.Kind, err))
+	}
+}
+
+func (a *App) headHash() string {
+	head, _ := a.GitClient.GetLastCommitHash()
+	return head
+}
+
+func (a *App) forcePush(pushCmd, spinnerText, successMsg string) error {
+	remote := pushRemote(pushCmd)
+	branch, _ := a.GitClient.GetCurrentBranch()
+	before, _ := a.GitClient.ResolveRef("refs/remotes/" + remote + "/" + branch)
+	if err := a.doPush(pushCmd, spinnerText, successMsg); err != nil {
+		return err
+	}
+	a.record(git.Operation{Kind: git.OpForcePush, Branch: branch, Remote: remote, Before: before, After: a.headHash()})
+	return nil
+}
+
+func pushRemote(pushCmd string) string {
+	for i, arg := range strings.Fields(pushCmd) {
+		if i > 1 && !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+	}
+	return "origin"
+}
+
+func (a *App) RunUndo() error {
+	op, ok, err := a.GitClient.LastOperation()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("undo: no yawn operation recorded in this repository")
+	}
+	ui.PrintInfo("Last operation: " + describeOperation(op))
+
+	switch op.Kind {
+	case git.OpRemoteURL:
+		err = a.undoRemoteURL(op)
+	case git.OpForcePush:
+		err = a.undoForcePush(op)
+	default:
+		err = a.undoHeadMove(op)
+	}
+	if err != nil {
+		return err
+	}
+	return a.GitClient.DropLastOperation()
+}
+
+func describeOperation(op git.Operation) string {
+	before, after := shortHash(op.Before), shortHash(op.After)
+	if op.Kind == git.OpRemoteURL {
+		before, after = op.Before, op.After
+	}
+	if before == "" {
+		before = "nothing"
+	}
+	desc := fmt.Sprintf("%s on %s at %s (%s -> %s)", op.Kind, op.Branch, op.Time.Local().Format("2006-01-02 15:04"), before, after)
+	if op.Kind == git.OpRemoteURL || op.Kind == git.OpForcePush {
+		desc = fmt.Sprintf("%s of %s at %s (%s -> %s)", op.Kind, op.Remote, op.Time.Local().Format("2006-01-02 15:04"), before, after)
+	}
+	if op.BackupRef != "" {
+		desc += ", backup " + op.BackupRef
+	}
+	if op.StashID != "" {
+		desc += ", stash " + shortHash(op.StashID)
+	}
+	return desc
+}
+
+func (a *App) undoHeadMove(op git.Operation) error {
+	if branch, _ := a.GitClient.GetCurrentBranch(); op.Branch != "" && branch != op.Branch {
+		return fmt.Errorf("undo: the last %s was made on %s, switch to it first", op.Kind, op.Branch)
+	}
+	if head := a.headHash(); head != op.After {
+		return fmt.Errorf("undo: HEAD is at %s, not at %s left by the last %s", shortHash(head), shortHash(op.After), op.Kind)
+	}
+	pushed, err := a.GitClient.IsPushed(op.After)
+	if err != nil {
+		return err
+	}
+	if pushed {
+		ui.PrintError(fmt.Sprintf("The %s was already pushed. Undo only rewrites local history, so the remote will need a force-push afterwards.", op.Kind))
+	}
+
+	target := fmt.Sprintf("%s, before the %s", shortHash(op.Before), op.Kind)
+	if op.Before == "" {
+		target = "no commits"
+	}
+	if !ui.AskYesNo(fmt.Sprintf("Move %s back to %s?", op.Branch, target), !pushed) {
+		return fmt.Errorf("undo: cancelled")
+	}
+	if err := a.GitClient.MoveHead(op.After, op.Before); err != nil {
+		return err
+	}
+	ui.PrintSuccess(fmt.Sprintf("Undid the %s. The working tree and index were left as they are.", op.Kind))
+	return nil
+}
+
+func (a *App) undoForcePush(op git.Operation) error {
+	if op.Before == "" {
+		return fmt.Errorf("undo: %s did not exist on %s before the force-push; delete it with `git push %s --delete %s` if needed", op.Branch, op.Remote, op.Remote, op.Branch)
+	}
+	pushCmd := fmt.Sprintf("git push --force-with-lease=refs/heads/%s:%s %s %s:refs/heads/%s", op.Branch, op.After, op.Remote, op.Before, op.Branch)
+	ui.PrintError(fmt.Sprintf("This overwrites %s on %s again, putting back %s.", op.Branch, op.Remote, shortHash(op.Before)))
+	if !ui.AskYesNo(fmt.Sprintf("Restore the remote branch? (using: %s)", pushCmd), false) {
+		return fmt.Errorf("undo: cancelled")
+	}
+	return a.doPush(pushCmd, "Restoring remote branch...", "Successfully restored the remote branch.")
+}
+
+func (a *App) undoRemoteURL(op git.Operation) error {
+	current, err := a.GitClient.GetRemoteURL(op.Remote)
+	if err != nil {
+		return err
+	}
+	if current != op.After {
+		return fmt.Errorf("undo: %s now points to %s, not %s", op.Remote, current, op.After)
+	}
+	if !ui.AskYesNo(fmt.Sprintf("Set %s back to %s?", op.Remote, op.Before), true) {
+		return fmt.Errorf("undo: cancelled")
+	}
+	if err := a.GitClient.SetRemoteURL(op.Remote, op.Before); err != nil {
+		return err
+	}
+	ui.PrintSuccess(fmt.Sprintf("Remote '%s' set back to %s", op.Remote, op.Before))
+	return nil
+}
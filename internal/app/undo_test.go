@@ -0,0 +1,143 @@
+package app
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mayurifag/yawn/internal/git"
+	"github.com/Mayurifag/yawn/internal/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePusher struct {
+	commands []string
+}
+
+func (p *fakePusher) ExecutePush(command string) (*git.PushResult, error) {
+	p.commands = append(p.commands, command)
+	return &git.PushResult{Success: true}, nil
+}
+
+func (p *fakePusher) HasRemotes() (bool, error) {
+	return true, nil
+}
+
+func runUndoWithInput(t *testing.T, a *App, input string) (string, error) {
+	ui.SetInput(strings.NewReader(input))
+	t.Cleanup(func() { ui.SetInput(os.Stdin) })
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	output := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		output <- string(b)
+	}()
+
+	undoErr := a.RunUndo()
+	os.Stdout = stdout
+	_ = w.Close()
+	return <-output, undoErr
+}
+
+func undoMock(op git.Operation, pushed bool) (*git.MockGitClient, *[]string, *bool) {
+	var moves []string
+	dropped := false
+	return &git.MockGitClient{
+		MockLastOperation:     func() (git.Operation, bool, error) { return op, true, nil },
+		MockGetCurrentBranch:  func() (string, error) { return op.Branch, nil },
+		MockGetLastCommitHash: func() (string, error) { return op.After, nil },
+		MockIsPushed:          func(string) (bool, error) { return pushed, nil },
+		MockMoveHead: func(from, to string) error {
+			moves = append(moves, from+" -> "+to)
+			return nil
+		},
+		MockDropLastOperation: func() error {
+			dropped = true
+			return nil
+		},
+	}, &moves, &dropped
+}
+
+func TestPushRemote(t *testing.T) {
+	assert.Equal(t, "origin", pushRemote("git push"))
+	assert.Equal(t, "upstream", pushRemote("git push upstream HEAD --force-with-lease"))
+	assert.Equal(t, "fork", pushRemote("git push --force-with-lease fork HEAD"))
+}
+
+func TestRunUndoRefusesWhenHeadMoved(t *testing.T) {
+	dropped := false
+	moved := false
+	mockGit := &git.MockGitClient{
+		MockLastOperation: func() (git.Operation, bool, error) {
+			return git.Operation{Kind: git.OpSquash, Branch: "feat", Before: "aaaaaaaaa", After: "bbbbbbbbb", Time: time.Now()}, true, nil
+		},
+		MockGetCurrentBranch:  func() (string, error) { return "feat", nil },
+		MockGetLastCommitHash: func() (string, error) { return "ccccccccc", nil },
+		MockMoveHead: func(from, to string) error {
+			moved = true
+			return nil
+		},
+		MockDropLastOperation: func() error {
+			dropped = true
+			return nil
+		},
+	}
+	a := &App{GitClient: mockGit}
+
+	assert.ErrorContains(t, a.RunUndo(), "HEAD is at ccccccc, not at bbbbbbb left by the last squash")
+	assert.False(t, moved)
+	assert.False(t, dropped)
+
+	mockGit.MockGetCurrentBranch = func() (string, error) { return "main", nil }
+	assert.ErrorContains(t, a.RunUndo(), "was made on feat, switch to it first")
+}
+
+func TestRunUndoWithoutJournal(t *testing.T) {
+	a := &App{GitClient: &git.MockGitClient{}}
+	assert.ErrorContains(t, a.RunUndo(), "no yawn operation recorded")
+}
+
+func TestRunUndoMovesHeadBack(t *testing.T) {
+	mockGit, moves, dropped := undoMock(git.Operation{Kind: git.OpCommit, Branch: "feat", Before: "aaaaaaaaa", After: "bbbbbbbbb", Time: time.Now()}, false)
+	a := &App{GitClient: mockGit}
+
+	output, err := runUndoWithInput(t, a, "\n")
+
+	require.NoError(t, err)
+	assert.Contains(t, output, "Move feat back to aaaaaaa, before the commit? [Y/n]")
+	assert.Equal(t, []string{"bbbbbbbbb -> aaaaaaaaa"}, *moves)
+	assert.True(t, *dropped)
+}
+
+func TestRunUndoPushedOperationDefaultsToNo(t *testing.T) {
+	mockGit, moves, dropped := undoMock(git.Operation{Kind: git.OpAmend, Branch: "feat", Before: "aaaaaaaaa", After: "bbbbbbbbb", Time: time.Now()}, true)
+	a := &App{GitClient: mockGit}
+
+	output, err := runUndoWithInput(t, a, "\n")
+
+	assert.ErrorContains(t, err, "undo: cancelled")
+	assert.Contains(t, output, "Move feat back to aaaaaaa, before the amend? [y/N]")
+	assert.Empty(t, *moves)
+	assert.False(t, *dropped)
+}
+
+func TestRunUndoForcePushUsesLease(t *testing.T) {
+	mockGit, moves, dropped := undoMock(git.Operation{Kind: git.OpForcePush, Branch: "feat", Remote: "origin", Before: "aaaaaaaaa", After: "bbbbbbbbb", Time: time.Now()}, true)
+	pusher := &fakePusher{}
+	a := &App{GitClient: mockGit, Pusher: pusher}
+
+	output, err := runUndoWithInput(t, a, "y\n")
+
+	require.NoError(t, err)
+	assert.Contains(t, output, "[y/N]")
+	assert.Equal(t, []string{"git push --force-with-lease=refs/heads/feat:bbbbbbbbb origin aaaaaaaaa:refs/heads/feat"}, pusher.commands)
+	assert.Empty(t, *moves)
+	assert.True(t, *dropped)
+}
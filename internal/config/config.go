@@ -13,39 +13,139 @@ const (
 	EnvPrefix               = "YAWN_"
 	ProviderGemini          = "gemini"
 	ProviderOpenCodeCLI     = "opencode_cli"
+	ProviderOpenAICompat    = "openai_compatible"
+	ProviderOllama          = "ollama"
+	ProviderAnthropic       = "anthropic"
+	ProviderCommand         = "command"
 	DefaultProvider         = ProviderGemini
 	DefaultGeminiModel      = "gemini-flash-latest"
 	DefaultOpenCodeCLIModel = "openai/gpt-5.3-codex-spark"
+	DefaultOllamaModel      = "qwen2.5-coder:7b"
+	DefaultOllamaHost       = "http://localhost:11434"
+	DefaultAnthropicModel   = "claude-haiku-4-5"
 	DefaultTimeoutSecs      = 15
 	DefaultAutoStage        = false
+	DefaultStageMode        = StageModeAll
 	DefaultAutoPush         = false
 	DefaultPushCommand      = "git push origin HEAD"
 	DefaultWaitForSSHKeys   = false
 	DefaultSquashAutoPush   = false
+	DefaultSummaryWorkers   = 3
+	DefaultSummaryChunkSize = 60000
+	DefaultEntropyThreshold = 4.5
+	DefaultMaxStageFileMB   = 50
+
+	StageModeAll  = "all"
+	StageModePick = "pick"
+
+	GuardActionPrompt = "prompt"
+	GuardActionRefuse = "refuse"
+
+	CommandPromptStdin     = "stdin"
+	CommandPromptFile      = "file"
+	CommandOutputText      = "text"
+	CommandOutputJSONLines = "json_lines"
+	CommandOutputSSE       = "sse"
 )
 
+var DefaultDiffRanking = DiffRanking{
+	Source:    100,
+	Test:      60,
+	Docs:      30,
+	Fixture:   20,
+	Generated: 10,
+	Churn:     3,
+}
+
+var DefaultSecretScan = SecretScan{
+	Enabled:          true,
+	EntropyThreshold: DefaultEntropyThreshold,
+}
+
+var DefaultStageGuard = StageGuard{
+	Enabled:   true,
+	Action:    GuardActionPrompt,
+	MaxFileMB: DefaultMaxStageFileMB,
+}
+
 type CLIFlags struct {
 	APIKey    *string
 	AutoStage *bool
+	StageMode *string
 	AutoPush  *bool
 }
 
 type ProviderConfig struct {
-	APIKey string `toml:"api_key"`
-	Model  string `toml:"model"`
+	Type    string            `toml:"type"`
+	APIKey  string            `toml:"api_key"`
+	Model   string            `toml:"model"`
+	BaseURL string            `toml:"base_url"`
+	Headers map[string]string `toml:"headers"`
+
+	TimeoutSeconds int `toml:"timeout_seconds"`
+
+	Host      string `toml:"host"`
+	KeepAlive string `toml:"keep_alive"`
+	NumCtx    int    `toml:"num_ctx"`
+
+	Command     []string `toml:"command"`
+	PromptInput string   `toml:"prompt_input"`
+	Output      string   `toml:"output"`
+	OutputField string   `toml:"output_field"`
+}
+
+type DiffRanking struct {
+	Source    float64 `toml:"source"`
+	Test      float64 `toml:"test"`
+	Docs      float64 `toml:"docs"`
+	Fixture   float64 `toml:"fixture"`
+	Generated float64 `toml:"generated"`
+	Churn     float64 `toml:"churn"`
+}
+
+type Redaction struct {
+	Lockfiles   []string `toml:"lockfiles"`
+	Skip        []string `toml:"skip"`
+	SummaryOnly []string `toml:"summary_only"`
+	Generated   []string `toml:"generated"`
+	Include     []string `toml:"include"`
+}
+
+type SecretScan struct {
+	Enabled          bool              `toml:"enabled"`
+	BlockAdded       bool              `toml:"block_added"`
+	EntropyThreshold float64           `toml:"entropy_threshold"`
+	Patterns         map[string]string `toml:"patterns"`
+}
+
+type StageGuard struct {
+	Enabled   bool     `toml:"enabled"`
+	Action    string   `toml:"action"`
+	Deny      []string `toml:"deny"`
+	MaxFileMB int      `toml:"max_file_mb"`
 }
 
 type Config struct {
 	MainProvider          string                    `toml:"main_provider"`
 	FallbackProvider      string                    `toml:"fallback_provider"`
+	ProvidersChain        []string                  `toml:"providers_chain"`
+	HedgeAfterMs          int                       `toml:"hedge_after_ms"`
 	Providers             map[string]ProviderConfig `toml:"providers"`
 	RequestTimeoutSeconds int                       `toml:"request_timeout_seconds"`
 	Prompt                string                    `toml:"prompt,multiline"`
 	AutoStage             bool                      `toml:"auto_stage"`
+	StageMode             string                    `toml:"stage_mode"`
 	AutoPush              bool                      `toml:"auto_push"`
 	PushCommand           string                    `toml:"push_command"`
 	WaitForSSHKeys        bool                      `toml:"wait_for_ssh_keys"`
 	SquashAutoPush        bool                      `toml:"squash_auto_push"`
+	SummarizeLargeDiffs   bool                      `toml:"summarize_large_diffs"`
+	SummaryConcurrency    int                       `toml:"summary_concurrency"`
+	SummaryChunkBytes     int                       `toml:"summary_chunk_bytes"`
+	DiffRanking           DiffRanking               `toml:"diff_ranking"`
+	Redaction             Redaction                 `toml:"redaction"`
+	SecretScan            SecretScan                `toml:"secret_scan"`
+	StageGuard            StageGuard                `toml:"stage_guard"`
 
 	sources map[string]string `toml:"-"`
 }
@@ -57,19 +157,32 @@ func defaultConfig() Config {
 		RequestTimeoutSeconds: DefaultTimeoutSecs,
 		Prompt:                DefaultPrompt,
 		AutoStage:             DefaultAutoStage,
+		StageMode:             DefaultStageMode,
 		AutoPush:              DefaultAutoPush,
 		PushCommand:           DefaultPushCommand,
 		WaitForSSHKeys:        DefaultWaitForSSHKeys,
 		SquashAutoPush:        DefaultSquashAutoPush,
+		SummaryConcurrency:    DefaultSummaryWorkers,
+		SummaryChunkBytes:     DefaultSummaryChunkSize,
+		DiffRanking:           DefaultDiffRanking,
+		SecretScan:            DefaultSecretScan,
+		StageGuard:            DefaultStageGuard,
 	}
 }
 
 func NormalizeProvider(provider string) string {
-	provider = strings.ToLower(strings.TrimSpace(provider))
-	return strings.ReplaceAll(provider, "-", "_")
+	return normalizeOption(provider)
+}
+
+func normalizeOption(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	return strings.ReplaceAll(value, "-", "_")
 }
 
 func (c Config) GetMainProvider() string {
+	if chain := c.configuredChain(); len(chain) > 0 {
+		return chain[0]
+	}
 	provider := NormalizeProvider(c.MainProvider)
 	if provider == "" {
 		return DefaultProvider
@@ -81,6 +194,31 @@ func (c Config) GetFallbackProvider() string {
 	return NormalizeProvider(c.FallbackProvider)
 }
 
+func (c Config) GetProviderChain() []string {
+	if chain := c.configuredChain(); len(chain) > 0 {
+		return chain
+	}
+	chain := []string{c.GetMainProvider()}
+	if fallbackProvider := c.GetFallbackProvider(); fallbackProvider != "" && fallbackProvider != chain[0] {
+		chain = append(chain, fallbackProvider)
+	}
+	return chain
+}
+
+func (c Config) configuredChain() []string {
+	var chain []string
+	seen := map[string]bool{}
+	for _, provider := range c.ProvidersChain {
+		provider = NormalizeProvider(provider)
+		if provider == "" || seen[provider] {
+			continue
+		}
+		seen[provider] = true
+		chain = append(chain, provider)
+	}
+	return chain
+}
+
 func (c *Config) SetProviderConfig(provider string, providerCfg ProviderConfig) {
 	provider = NormalizeProvider(provider)
 	if c.Providers == nil {
@@ -91,20 +229,84 @@ func (c *Config) SetProviderConfig(provider string, providerCfg ProviderConfig)
 
 func (c Config) GetProviderConfig(provider string) ProviderConfig {
 	provider = NormalizeProvider(provider)
-	providerCfg := defaultProviderConfig(provider)
 	loadedProviderCfg := c.Providers[provider]
-	if loadedProviderCfg.APIKey != "" {
-		providerCfg.APIKey = loadedProviderCfg.APIKey
-	}
-	if loadedProviderCfg.Model != "" {
-		providerCfg.Model = loadedProviderCfg.Model
-	}
+	providerType := providerTypeOf(provider, loadedProviderCfg)
+	providerCfg := defaultProviderConfig(providerType)
+	providerCfg.overlay(loadedProviderCfg)
+	providerCfg.Type = providerType
 	if providerCfg.Model == "" {
-		providerCfg.Model = defaultProviderModel(provider)
+		providerCfg.Model = defaultProviderModel(providerType)
 	}
 	return providerCfg
 }
 
+func (p *ProviderConfig) overlay(loaded ProviderConfig) {
+	overlayString(&p.Type, loaded.Type)
+	overlayString(&p.APIKey, loaded.APIKey)
+	overlayString(&p.Model, loaded.Model)
+	overlayString(&p.BaseURL, loaded.BaseURL)
+	overlayString(&p.Host, loaded.Host)
+	overlayString(&p.KeepAlive, loaded.KeepAlive)
+	overlayString(&p.PromptInput, loaded.PromptInput)
+	overlayString(&p.Output, loaded.Output)
+	overlayString(&p.OutputField, loaded.OutputField)
+	if loaded.NumCtx > 0 {
+		p.NumCtx = loaded.NumCtx
+	}
+	if loaded.TimeoutSeconds > 0 {
+		p.TimeoutSeconds = loaded.TimeoutSeconds
+	}
+	if len(loaded.Command) > 0 {
+		p.Command = loaded.Command
+	}
+	if len(loaded.Headers) > 0 {
+		headers := make(map[string]string, len(p.Headers)+len(loaded.Headers))
+		for key, value := range p.Headers {
+			headers[key] = value
+		}
+		for key, value := range loaded.Headers {
+			headers[key] = value
+		}
+		p.Headers = headers
+	}
+}
+
+func overlayString(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
+
+func (c Config) GetProviderType(provider string) string {
+	provider = NormalizeProvider(provider)
+	return providerTypeOf(provider, c.Providers[provider])
+}
+
+func providerTypeOf(provider string, providerCfg ProviderConfig) string {
+	if providerType := NormalizeProvider(providerCfg.Type); providerType != "" {
+		return providerType
+	}
+	return provider
+}
+
+func (p ProviderConfig) GetPromptInput() string {
+	if p.PromptInput == "" {
+		return CommandPromptStdin
+	}
+	return normalizeOption(p.PromptInput)
+}
+
+func (p ProviderConfig) GetOutput() string {
+	if p.Output == "" {
+		return CommandOutputText
+	}
+	return normalizeOption(p.Output)
+}
+
+func (p ProviderConfig) GetTimeout() time.Duration {
+	return time.Duration(p.TimeoutSeconds) * time.Second
+}
+
 func (c Config) GetAPIKey() string {
 	return c.GetProviderConfig(c.GetMainProvider()).APIKey
 }
@@ -126,6 +328,10 @@ func defaultProviderConfig(provider string) ProviderConfig {
 		return ProviderConfig{Model: DefaultGeminiModel}
 	case ProviderOpenCodeCLI:
 		return ProviderConfig{Model: DefaultOpenCodeCLIModel}
+	case ProviderOllama:
+		return ProviderConfig{Model: DefaultOllamaModel, Host: DefaultOllamaHost}
+	case ProviderAnthropic:
+		return ProviderConfig{Model: DefaultAnthropicModel}
 	default:
 		return ProviderConfig{}
 	}
@@ -137,6 +343,10 @@ func defaultProviderModel(provider string) string {
 		return DefaultGeminiModel
 	case ProviderOpenCodeCLI:
 		return DefaultOpenCodeCLIModel
+	case ProviderOllama:
+		return DefaultOllamaModel
+	case ProviderAnthropic:
+		return DefaultAnthropicModel
 	default:
 		return ""
 	}
@@ -144,7 +354,7 @@ func defaultProviderModel(provider string) string {
 
 func ProviderRequiresAPIKey(provider string) bool {
 	switch NormalizeProvider(provider) {
-	case ProviderGemini:
+	case ProviderGemini, ProviderAnthropic:
 		return true
 	default:
 		return false
@@ -152,12 +362,11 @@ func ProviderRequiresAPIKey(provider string) bool {
 }
 
 func (c Config) GetModelLabel() string {
-	label := c.GetMainProvider() + "/" + c.GetModel()
-	if fallbackProvider := c.GetFallbackProvider(); fallbackProvider != "" {
-		fallbackCfg := c.GetProviderConfig(fallbackProvider)
-		label += " -> " + fallbackProvider + "/" + fallbackCfg.Model
+	var links []string
+	for _, provider := range c.GetProviderChain() {
+		links = append(links, provider+"/"+c.GetProviderConfig(provider).Model)
 	}
-	return label
+	return strings.Join(links, " -> ")
 }
 
 func ProviderDisplayName(provider string) string {
@@ -166,6 +375,14 @@ func ProviderDisplayName(provider string) string {
 		return "Google Gemini"
 	case ProviderOpenCodeCLI:
 		return "OpenCode CLI"
+	case ProviderOpenAICompat:
+		return "OpenAI-compatible API"
+	case ProviderOllama:
+		return "Ollama"
+	case ProviderAnthropic:
+		return "Anthropic"
+	case ProviderCommand:
+		return "Command"
 	default:
 		return NormalizeProvider(provider)
 	}
@@ -177,6 +394,12 @@ func ProviderAPIKeyHelp(provider string) string {
 		return "Get one from: https://makersuite.google.com/app/apikey"
 	case ProviderOpenCodeCLI:
 		return "Run: opencode providers login"
+	case ProviderOpenAICompat:
+		return "Set api_key in the provider section of your yawn config"
+	case ProviderOllama:
+		return "Run: ollama serve && ollama pull " + DefaultOllamaModel
+	case ProviderAnthropic:
+		return "Get one from: https://console.anthropic.com/settings/keys"
 	default:
 		return "Set api_key in your yawn config"
 	}
@@ -186,6 +409,38 @@ func (c Config) GetRequestTimeout() time.Duration {
 	return time.Duration(c.RequestTimeoutSeconds) * time.Second
 }
 
+func (c Config) GetHedgeAfter() time.Duration {
+	return time.Duration(c.HedgeAfterMs) * time.Millisecond
+}
+
+func (c Config) GetStageMode() string {
+	if mode := normalizeOption(c.StageMode); mode == StageModePick {
+		return mode
+	}
+	return StageModeAll
+}
+
+func (g StageGuard) GetAction() string {
+	if action := normalizeOption(g.Action); action == GuardActionRefuse {
+		return action
+	}
+	return GuardActionPrompt
+}
+
+func (c Config) GetSummaryConcurrency() int {
+	if c.SummaryConcurrency <= 0 {
+		return DefaultSummaryWorkers
+	}
+	return c.SummaryConcurrency
+}
+
+func (c Config) GetSummaryChunkBytes() int {
+	if c.SummaryChunkBytes <= 0 {
+		return DefaultSummaryChunkSize
+	}
+	return c.SummaryChunkBytes
+}
+
 func (c Config) GetConfigSource(option string) string {
 	if source, ok := c.sources[option]; ok {
 		return source
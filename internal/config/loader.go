@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -26,6 +27,18 @@ var envFields = []envField{
 		c.FallbackProvider = v
 		return true
 	}},
+	{EnvPrefix + "PROVIDERS_CHAIN", "ProvidersChain", func(c *Config, v string) bool {
+		c.ProvidersChain = strings.Split(v, ",")
+		return true
+	}},
+	{EnvPrefix + "HEDGE_AFTER_MS", "HedgeAfterMs", func(c *Config, v string) bool {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return false
+		}
+		c.HedgeAfterMs = n
+		return true
+	}},
 	{EnvPrefix + "GEMINI_API_KEY", "Providers", func(c *Config, v string) bool {
 		setProviderAPIKey(c, ProviderGemini, v)
 		return true
@@ -38,6 +51,22 @@ var envFields = []envField{
 		setProviderModel(c, ProviderOpenCodeCLI, v)
 		return true
 	}},
+	{EnvPrefix + "ANTHROPIC_API_KEY", "Providers", func(c *Config, v string) bool {
+		setProviderAPIKey(c, ProviderAnthropic, v)
+		return true
+	}},
+	{EnvPrefix + "ANTHROPIC_MODEL", "Providers", func(c *Config, v string) bool {
+		setProviderModel(c, ProviderAnthropic, v)
+		return true
+	}},
+	{EnvPrefix + "OLLAMA_MODEL", "Providers", func(c *Config, v string) bool {
+		setProviderModel(c, ProviderOllama, v)
+		return true
+	}},
+	{EnvPrefix + "OLLAMA_HOST", "Providers", func(c *Config, v string) bool {
+		setProviderHost(c, ProviderOllama, v)
+		return true
+	}},
 	{EnvPrefix + "PROMPT", "Prompt", func(c *Config, v string) bool {
 		c.Prompt = v
 		return true
@@ -62,6 +91,10 @@ var envFields = []envField{
 		c.AutoStage = b
 		return true
 	}},
+	{EnvPrefix + "STAGE_MODE", "StageMode", func(c *Config, v string) bool {
+		c.StageMode = v
+		return true
+	}},
 	{EnvPrefix + "AUTO_PUSH", "AutoPush", func(c *Config, v string) bool {
 		b, err := strconv.ParseBool(v)
 		if err != nil {
@@ -86,6 +119,54 @@ var envFields = []envField{
 		c.SquashAutoPush = b
 		return true
 	}},
+	{EnvPrefix + "SUMMARIZE_LARGE_DIFFS", "SummarizeLargeDiffs", func(c *Config, v string) bool {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false
+		}
+		c.SummarizeLargeDiffs = b
+		return true
+	}},
+	{EnvPrefix + "SUMMARY_CONCURRENCY", "SummaryConcurrency", func(c *Config, v string) bool {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return false
+		}
+		c.SummaryConcurrency = n
+		return true
+	}},
+	{EnvPrefix + "SUMMARY_CHUNK_BYTES", "SummaryChunkBytes", func(c *Config, v string) bool {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return false
+		}
+		c.SummaryChunkBytes = n
+		return true
+	}},
+	{EnvPrefix + "SECRET_SCAN", "SecretScan", func(c *Config, v string) bool {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false
+		}
+		c.SecretScan.Enabled = b
+		return true
+	}},
+	{EnvPrefix + "STAGE_GUARD", "StageGuard", func(c *Config, v string) bool {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false
+		}
+		c.StageGuard.Enabled = b
+		return true
+	}},
+	{EnvPrefix + "BLOCK_SECRETS", "SecretScan", func(c *Config, v string) bool {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false
+		}
+		c.SecretScan.BlockAdded = b
+		return true
+	}},
 }
 
 func setProviderAPIKey(cfg *Config, provider, apiKey string) {
@@ -102,6 +183,13 @@ func setProviderModel(cfg *Config, provider, model string) {
 	cfg.SetProviderConfig(provider, providerCfg)
 }
 
+func setProviderHost(cfg *Config, provider, host string) {
+	provider = NormalizeProvider(provider)
+	providerCfg := cfg.Providers[provider]
+	providerCfg.Host = host
+	cfg.SetProviderConfig(provider, providerCfg)
+}
+
 func getUserConfigPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -170,10 +258,31 @@ func loadProjectConfig(projectPath string) (Config, toml.MetaData, error) {
 	if decodeErr != nil {
 		return Config{}, toml.MetaData{}, fmt.Errorf("failed to load project config from %s: %w", projectConfigPath, decodeErr)
 	}
+	if err := checkProjectProviders(projectConfigPath, loadedCfg.Providers, metadata); err != nil {
+		return Config{}, toml.MetaData{}, err
+	}
 
 	return loadedCfg, metadata, nil
 }
 
+var userOnlyProviderKeys = []string{"type", "api_key", "base_url", "host", "headers", "command", "prompt_input", "output", "output_field"}
+
+func checkProjectProviders(path string, providers map[string]ProviderConfig, meta toml.MetaData) error {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, key := range userOnlyProviderKeys {
+			if meta.IsDefined("providers", name, key) {
+				return fmt.Errorf("project config %s cannot set providers.%s.%s; move it to the user config or env", path, name, key)
+			}
+		}
+	}
+	return nil
+}
+
 func mergeConfig(base *Config, loaded Config, meta toml.MetaData, src string) {
 	bv := reflect.ValueOf(base).Elem()
 	lv := reflect.ValueOf(loaded)
@@ -193,11 +302,26 @@ func mergeConfig(base *Config, loaded Config, meta toml.MetaData, src string) {
 			base.sources[field.Name] = src
 			continue
 		}
+		if field.Type.Kind() == reflect.Struct {
+			mergeTable(bv.Field(i), lv.Field(i), meta, tomlKey)
+			base.sources[field.Name] = src
+			continue
+		}
 		bv.Field(i).Set(lv.Field(i))
 		base.sources[field.Name] = src
 	}
 }
 
+func mergeTable(base, loaded reflect.Value, meta toml.MetaData, tableKey string) {
+	t := base.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.SplitN(t.Field(i).Tag.Get("toml"), ",", 2)[0]
+		if key != "" && meta.IsDefined(tableKey, key) {
+			base.Field(i).Set(loaded.Field(i))
+		}
+	}
+}
+
 func mergeProviders(base *Config, loadedProviders map[string]ProviderConfig) {
 	if base.Providers == nil {
 		base.Providers = map[string]ProviderConfig{}
@@ -205,12 +329,7 @@ func mergeProviders(base *Config, loadedProviders map[string]ProviderConfig) {
 	for provider, loadedProvider := range loadedProviders {
 		provider = NormalizeProvider(provider)
 		providerCfg := base.Providers[provider]
-		if loadedProvider.APIKey != "" {
-			providerCfg.APIKey = loadedProvider.APIKey
-		}
-		if loadedProvider.Model != "" {
-			providerCfg.Model = loadedProvider.Model
-		}
+		providerCfg.overlay(loadedProvider)
 		base.Providers[provider] = providerCfg
 	}
 }
@@ -234,6 +353,10 @@ func applyFlags(cfg *Config, flags CLIFlags) {
 		cfg.AutoStage = *flags.AutoStage
 		cfg.sources["AutoStage"] = "flag"
 	}
+	if flags.StageMode != nil {
+		cfg.StageMode = *flags.StageMode
+		cfg.sources["StageMode"] = "flag"
+	}
 	if flags.AutoPush != nil {
 		cfg.AutoPush = *flags.AutoPush
 		cfg.sources["AutoPush"] = "flag"
@@ -38,3 +38,22 @@ feat!: allow provided config object to extend other configs
 
 BREAKING CHANGE: 'extends' key in config file is now used for extending other config files
 =`
+
+const SummaryPrompt = `Summarize this part of a larger git diff for someone who will write the commit message.
+
+- List each meaningful change as a short bullet point, naming the files or symbols involved.
+- Focus on intent and behavior, not line-by-line edits.
+- Skip formatting-only and trivial changes.
+- Only output the bullet points.`
+
+const SplitPrompt = `Split these staged changes into small, logical commits.
+
+- Group files that belong to the same change. Unrelated changes go to separate commits.
+- Each hunk in the diff is labelled ### path#N. A file may be split by hunk with path#N, using exactly those labels. Only do this when one file mixes unrelated changes.
+- Every file or hunk appears in exactly one commit. Order commits so that each one builds on the previous ones.
+- Each message is a single Conventional Commits subject line under 72 characters. Type, scope and description start with a lowercase letter.
+- Only output the plan in this format, nothing else:
+
+commit <type>[optional scope]: <description>
+  <path>
+  <path>#<hunk>`
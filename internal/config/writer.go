@@ -32,14 +32,20 @@ func GenerateConfigContent(apiKey string) ([]byte, error) {
 	buf.WriteString("# Configuration file for yawn - AI Git Committer\n#\n# Placement:\n#   ~/.config/yawn/config.toml (user config)\n#   ./.yawn.toml (project config, add to .gitignore)\n#\n# Precedence: CLI flags > env vars > project config > user config > defaults\n# Uncomment and change only the values you want to override.\n\n")
 
 	fmt.Fprintf(&buf, "main_provider = %q\n", DefaultProvider)
-	buf.WriteString("# fallback_provider = \"opencode_cli\"\n\n")
+	buf.WriteString("# fallback_provider = \"opencode_cli\"\n")
+	buf.WriteString("# providers_chain = [\"ollama\", \"gemini\", \"opencode_cli\"] # overrides main/fallback\n")
+	buf.WriteString("# hedge_after_ms = 0 # start the next provider in parallel when no output arrives in time\n\n")
 
 	fmt.Fprintf(&buf, "# request_timeout_seconds = %d\n", DefaultTimeoutSecs)
 	fmt.Fprintf(&buf, "# auto_stage = %v\n", DefaultAutoStage)
+	fmt.Fprintf(&buf, "# stage_mode = %q # \"pick\" chooses files to stage interactively\n", DefaultStageMode)
 	fmt.Fprintf(&buf, "# auto_push = %v\n", DefaultAutoPush)
 	fmt.Fprintf(&buf, "# push_command = %q\n", DefaultPushCommand)
 	fmt.Fprintf(&buf, "# wait_for_ssh_keys = %v\n", DefaultWaitForSSHKeys)
 	fmt.Fprintf(&buf, "# squash_auto_push = %v\n", DefaultSquashAutoPush)
+	buf.WriteString("# summarize_large_diffs = false # summarize oversized diffs chunk by chunk instead of truncating\n")
+	fmt.Fprintf(&buf, "# summary_concurrency = %d\n", DefaultSummaryWorkers)
+	fmt.Fprintf(&buf, "# summary_chunk_bytes = %d\n", DefaultSummaryChunkSize)
 	buf.WriteString("\n")
 
 	buf.WriteString("# prompt = '''\n")
@@ -49,6 +55,38 @@ func GenerateConfigContent(apiKey string) ([]byte, error) {
 	buf.WriteString("# '''\n")
 	buf.WriteString("\n")
 
+	buf.WriteString("# Files are ranked before spending the diff budget: higher weights are shown first.\n")
+	buf.WriteString("# [diff_ranking]\n")
+	fmt.Fprintf(&buf, "# source = %v\n", DefaultDiffRanking.Source)
+	fmt.Fprintf(&buf, "# test = %v\n", DefaultDiffRanking.Test)
+	fmt.Fprintf(&buf, "# docs = %v\n", DefaultDiffRanking.Docs)
+	fmt.Fprintf(&buf, "# fixture = %v\n", DefaultDiffRanking.Fixture)
+	fmt.Fprintf(&buf, "# generated = %v\n", DefaultDiffRanking.Generated)
+	fmt.Fprintf(&buf, "# churn = %v # multiplied by log2 of changed lines\n", DefaultDiffRanking.Churn)
+	buf.WriteString("\n")
+	buf.WriteString("# Redaction policy: matching files are sent as a one-line summary instead of their diff.\n")
+	buf.WriteString("# Globs without a slash match the file name; ** matches any number of directories.\n")
+	buf.WriteString("# [redaction]\n")
+	buf.WriteString("# lockfiles = [\"deno.lock\"]\n")
+	buf.WriteString("# skip = [\"secrets/**\"]\n")
+	buf.WriteString("# summary_only = [\"db/schema.sql\"]\n")
+	buf.WriteString("# generated = [\"api/**/*.gen.ts\"]\n")
+	buf.WriteString("# include = [\"go.sum\"] # force a normally redacted file through\n")
+	buf.WriteString("\n")
+	buf.WriteString("# Secrets found in diff hunks are masked before anything is sent to the AI.\n")
+	buf.WriteString("# [secret_scan]\n")
+	fmt.Fprintf(&buf, "# enabled = %v\n", DefaultSecretScan.Enabled)
+	fmt.Fprintf(&buf, "# block_added = %v # refuse to commit when added lines contain secrets\n", DefaultSecretScan.BlockAdded)
+	fmt.Fprintf(&buf, "# entropy_threshold = %v # 0 disables the high-entropy check\n", DefaultSecretScan.EntropyThreshold)
+	buf.WriteString("# patterns = { internal_token = \"itk_[a-z0-9]{32}\" }\n")
+	buf.WriteString("\n")
+	buf.WriteString("# Untracked files are checked before auto-staging.\n")
+	buf.WriteString("# [stage_guard]\n")
+	fmt.Fprintf(&buf, "# enabled = %v\n", DefaultStageGuard.Enabled)
+	fmt.Fprintf(&buf, "# action = %q # or \"refuse\"\n", DefaultStageGuard.Action)
+	buf.WriteString("# deny = [\"*.sql.gz\", \"dumps/\"]\n")
+	fmt.Fprintf(&buf, "# max_file_mb = %d # 0 disables the size limit\n", DefaultStageGuard.MaxFileMB)
+	buf.WriteString("\n")
 	buf.WriteString("# Provider examples:\n")
 	fmt.Fprintf(&buf, "#   main_provider = %q\n", ProviderOpenCodeCLI)
 	fmt.Fprintf(&buf, "#   fallback_provider = %q\n\n", ProviderGemini)
@@ -58,7 +96,35 @@ func GenerateConfigContent(apiKey string) ([]byte, error) {
 	fmt.Fprintf(&buf, "# model = %q\n\n", DefaultGeminiModel)
 
 	buf.WriteString("# [providers.opencode_cli]\n")
-	fmt.Fprintf(&buf, "# model = %q\n", DefaultOpenCodeCLIModel)
+	fmt.Fprintf(&buf, "# model = %q\n\n", DefaultOpenCodeCLIModel)
+
+	buf.WriteString("# [providers.anthropic]\n")
+	buf.WriteString("# api_key = \"\"\n")
+	fmt.Fprintf(&buf, "# model = %q\n", DefaultAnthropicModel)
+	buf.WriteString("# base_url = \"https://api.anthropic.com\"\n\n")
+
+	buf.WriteString("# [providers.ollama]\n")
+	fmt.Fprintf(&buf, "# model = %q\n", DefaultOllamaModel)
+	fmt.Fprintf(&buf, "# host = %q\n", DefaultOllamaHost)
+	buf.WriteString("# timeout_seconds = 10 # per-link timeout inside a providers chain\n")
+	buf.WriteString("# keep_alive = \"5m\"\n")
+	buf.WriteString("# num_ctx = 16384\n\n")
+
+	buf.WriteString("# Any OpenAI-compatible server (OpenRouter, vLLM, LM Studio, llama.cpp) under a name of your choice:\n")
+	buf.WriteString("# [providers.openrouter]\n")
+	fmt.Fprintf(&buf, "# type = %q\n", ProviderOpenAICompat)
+	buf.WriteString("# base_url = \"https://openrouter.ai/api/v1\"\n")
+	buf.WriteString("# api_key = \"\"\n")
+	buf.WriteString("# model = \"qwen/qwen3-coder\"\n")
+	buf.WriteString("# headers = { \"X-Title\" = \"yawn\" }\n\n")
+
+	buf.WriteString("# Any local CLI that prints a commit message (llm, aichat, claude -p, gemini-cli, scripts):\n")
+	buf.WriteString("# [providers.llm]\n")
+	fmt.Fprintf(&buf, "# type = %q\n", ProviderCommand)
+	buf.WriteString("# command = [\"llm\", \"-m\", \"{model}\"]\n")
+	buf.WriteString("# model = \"gpt-4o-mini\"\n")
+	fmt.Fprintf(&buf, "# prompt_input = %q # or %q, passed as {prompt_file}\n", CommandPromptStdin, CommandPromptFile)
+	fmt.Fprintf(&buf, "# output = %q # or %q with output_field, or %q\n", CommandOutputText, CommandOutputJSONLines, CommandOutputSSE)
 
 	return buf.Bytes(), nil
 }
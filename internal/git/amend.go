@@ -0,0 +1,29 @@
+package git
+
+import "fmt"
+
+func (c *ExecGitClient) GetAmendBase() (string, error) {
+	if _, err := c.runGitCommand("rev-parse", "--verify", "HEAD"); err != nil {
+		return "", fmt.Errorf("no commit to amend: %w", err)
+	}
+	return c.parentOrEmptyTree("HEAD")
+}
+
+func (c *ExecGitClient) parentOrEmptyTree(rev string) (string, error) {
+	if parent, err := c.runGitCommand("rev-parse", "--verify", "--quiet", rev+"^"); err == nil {
+		return parent, nil
+	}
+	emptyTree, err := c.runGitCommand("hash-object", "-t", "tree", "/dev/null")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the empty tree: %w", err)
+	}
+	return emptyTree, nil
+}
+
+func (c *ExecGitClient) IsPushed(rev string) (bool, error) {
+	output, err := c.runGitCommand("branch", "-r", "--contains", rev, "--format=%(refname)")
+	if err != nil {
+		return false, fmt.Errorf("failed to check remote branches for %s: %w", rev, err)
+	}
+	return output != "", nil
+}
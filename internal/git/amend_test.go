@@ -0,0 +1,61 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAmendBase(t *testing.T) {
+	repo := newTestRepo(t)
+	client := &ExecGitClient{RepoPath: repo}
+
+	base, err := client.GetAmendBase()
+	require.NoError(t, err)
+	diff, err := client.GetDiffCachedRange(base)
+	require.NoError(t, err)
+	assert.Contains(t, diff.Text, "+test")
+
+	writeTestFile(t, repo, "main.go", "package main\n")
+	runTestGit(t, repo, "add", "main.go")
+	runTestGit(t, repo, "commit", "-m", "add main")
+
+	base, err = client.GetAmendBase()
+	require.NoError(t, err)
+	assert.Equal(t, runTestGit(t, repo, "rev-parse", "HEAD^"), base)
+	diff, err = client.GetDiffCachedRange(base)
+	require.NoError(t, err)
+	assert.Contains(t, diff.Text, "+package main")
+	assert.NotContains(t, diff.Text, "README.md")
+}
+
+func TestGetAmendBaseWithoutCommits(t *testing.T) {
+	repo := t.TempDir()
+	runTestGit(t, repo, "init", "-b", "master")
+	client := &ExecGitClient{RepoPath: repo}
+
+	_, err := client.GetAmendBase()
+	assert.ErrorContains(t, err, "no commit to amend")
+}
+
+func TestIsPushed(t *testing.T) {
+	repo := newTestRepo(t)
+	client := &ExecGitClient{RepoPath: repo}
+
+	pushed, err := client.IsPushed("HEAD")
+	require.NoError(t, err)
+	assert.False(t, pushed)
+
+	runTestGit(t, repo, "update-ref", "refs/remotes/origin/master", "HEAD")
+	pushed, err = client.IsPushed("HEAD")
+	require.NoError(t, err)
+	assert.True(t, pushed)
+
+	writeTestFile(t, repo, "main.go", "package main\n")
+	runTestGit(t, repo, "add", "main.go")
+	runTestGit(t, repo, "commit", "-m", "add main")
+	pushed, err = client.IsPushed("HEAD")
+	require.NoError(t, err)
+	assert.False(t, pushed)
+}
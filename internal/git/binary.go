@@ -0,0 +1,150 @@
+package git
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"strconv"
+	"strings"
+)
+
+const (
+	imageHeaderBytes = 1 << 20
+	lfsPointerBytes  = 1024
+	lfsPointerPrefix = "version https://git-lfs.github.com/spec/v1"
+)
+
+type blobInfo struct {
+	size  int64
+	image string
+}
+
+func isLFSPointer(content string) bool {
+	return strings.HasPrefix(content, lfsPointerPrefix)
+}
+
+func parseLFSPointerSize(content string) (int64, bool) {
+	if !isLFSPointer(content) {
+		return 0, false
+	}
+	for line := range strings.SplitSeq(content, "\n") {
+		if value, ok := strings.CutPrefix(line, "size "); ok {
+			size, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			return size, err == nil
+		}
+	}
+	return 0, false
+}
+
+func (c *ExecGitClient) describeBinary(src diffSource, f classifiedFile) string {
+	oldPath := f.entry.path
+	if f.entry.oldPath != "" {
+		oldPath = f.entry.oldPath
+	}
+	oldSpec, newSpec := src.oldRev+":"+oldPath, src.newRev+":"+f.entry.path
+	if f.category == catLFS {
+		before, hasBefore := c.lfsObjectSize(oldSpec)
+		after, hasAfter := c.lfsObjectSize(newSpec)
+		return "LFS object " + formatBlobChange(hasBefore, formatBytes(before), hasAfter, formatBytes(after))
+	}
+	before, hasBefore := c.blobInfo(oldSpec)
+	after, hasAfter := c.blobInfo(newSpec)
+	desc := formatBlobChange(hasBefore, formatBytes(before.size), hasAfter, formatBytes(after.size))
+	if before.image != "" || after.image != "" {
+		desc += ", " + formatBlobChange(before.image != "", before.image, after.image != "", after.image)
+	}
+	return desc
+}
+
+func (c *ExecGitClient) blobSize(spec string) (int64, bool) {
+	out, err := c.runGitCommand("cat-file", "-s", spec)
+	if err != nil {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(out, 10, 64)
+	return size, err == nil
+}
+
+func (c *ExecGitClient) blobInfo(spec string) (blobInfo, bool) {
+	size, ok := c.blobSize(spec)
+	if !ok {
+		return blobInfo{}, false
+	}
+	return blobInfo{size: size, image: describeImage([]byte(c.readBlob(spec, imageHeaderBytes)))}, true
+}
+
+func (c *ExecGitClient) lfsObjectSize(spec string) (int64, bool) {
+	if size, ok := parseLFSPointerSize(c.readBlob(spec, lfsPointerBytes)); ok {
+		return size, true
+	}
+	return c.blobSize(spec)
+}
+
+func describeImage(data []byte) string {
+	if width, height, ok := webpDimensions(data); ok {
+		return fmt.Sprintf("WEBP %dx%d", width, height)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %dx%d", strings.ToUpper(format), cfg.Width, cfg.Height)
+}
+
+func webpDimensions(data []byte) (int, int, bool) {
+	if len(data) < 30 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return 0, 0, false
+	}
+	payload := data[20:]
+	switch string(data[12:16]) {
+	case "VP8X":
+		return int(uint24(payload[4:7])) + 1, int(uint24(payload[7:10])) + 1, true
+	case "VP8L":
+		if payload[0] != 0x2f {
+			return 0, 0, false
+		}
+		bits := binary.LittleEndian.Uint32(payload[1:5])
+		return int(bits&0x3fff) + 1, int((bits>>14)&0x3fff) + 1, true
+	case "VP8 ":
+		if !bytes.Equal(payload[3:6], []byte{0x9d, 0x01, 0x2a}) {
+			return 0, 0, false
+		}
+		return int(binary.LittleEndian.Uint16(payload[6:8]) & 0x3fff), int(binary.LittleEndian.Uint16(payload[8:10]) & 0x3fff), true
+	}
+	return 0, 0, false
+}
+
+func uint24(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+}
+
+func formatBlobChange(hasBefore bool, before string, hasAfter bool, after string) string {
+	switch {
+	case hasBefore && hasAfter && before == after:
+		return before
+	case hasBefore && hasAfter:
+		return before + " -> " + after
+	case hasAfter:
+		return "added " + after
+	case hasBefore:
+		return "deleted " + before
+	}
+	return "size unknown"
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
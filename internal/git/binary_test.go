@@ -0,0 +1,85 @@
+package git
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPNG(t *testing.T, width, height int) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.String()
+}
+
+func testWebP(chunk string, payload []byte) []byte {
+	data := append([]byte("RIFF\x00\x00\x00\x00WEBP"+chunk+"\x00\x00\x00\x00"), payload...)
+	return append(data, make([]byte, 16)...)
+}
+
+func TestDescribeImage(t *testing.T) {
+	var gifBuf bytes.Buffer
+	require.NoError(t, gif.Encode(&gifBuf, image.NewPaletted(image.Rect(0, 0, 3, 7), color.Palette{color.Black}), nil))
+
+	vp8x := make([]byte, 10)
+	copy(vp8x[4:], []byte{0xff, 0x01, 0x00, 0x7f, 0x00, 0x00})
+	vp8l := make([]byte, 5)
+	vp8l[0] = 0x2f
+	binary.LittleEndian.PutUint32(vp8l[1:], uint32(640-1)|uint32(480-1)<<14)
+	vp8 := []byte{0, 0, 0, 0x9d, 0x01, 0x2a, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint16(vp8[6:], 320)
+	binary.LittleEndian.PutUint16(vp8[8:], 200)
+
+	assert.Equal(t, "PNG 16x9", describeImage([]byte(testPNG(t, 16, 9))))
+	assert.Equal(t, "GIF 3x7", describeImage(gifBuf.Bytes()))
+	assert.Equal(t, "WEBP 512x128", describeImage(testWebP("VP8X", vp8x)))
+	assert.Equal(t, "WEBP 640x480", describeImage(testWebP("VP8L", vp8l)))
+	assert.Equal(t, "WEBP 320x200", describeImage(testWebP("VP8 ", vp8)))
+	assert.Equal(t, "", describeImage([]byte("\x00\x01binary")))
+}
+
+func TestParseLFSPointerSize(t *testing.T) {
+	size, ok := parseLFSPointerSize("version https://git-lfs.github.com/spec/v1\noid sha256:4d7a2146\nsize 1048576\n")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1048576), size)
+
+	_, ok = parseLFSPointerSize("size 12\n")
+	assert.False(t, ok)
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", formatBytes(512))
+	assert.Equal(t, "1.5 KiB", formatBytes(1536))
+	assert.Equal(t, "3.0 MiB", formatBytes(3<<20))
+}
+
+func TestExecGitClient_GetDiffDescribesBinaries(t *testing.T) {
+	repo := newTestRepo(t)
+	writeTestFile(t, repo, "logo.png", testPNG(t, 256, 256))
+	writeTestFile(t, repo, "old.bin", "\x00\x01\x02\x03")
+	runTestGit(t, repo, "add", ".")
+	runTestGit(t, repo, "commit", "-m", "initial")
+
+	writeTestFile(t, repo, "logo.png", testPNG(t, 512, 512))
+	writeTestFile(t, repo, "model.onnx", "version https://git-lfs.github.com/spec/v1\noid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\nsize 5242880\n")
+	runTestGit(t, repo, "rm", "-q", "old.bin")
+	runTestGit(t, repo, "add", ".")
+
+	client := &ExecGitClient{RepoPath: repo}
+	diff, err := client.GetDiff()
+	require.NoError(t, err)
+
+	assert.Regexp(t, `logo\.png: binary, [\d.]+ KiB -> [\d.]+ KiB, PNG 256x256 -> PNG 512x512\n`, diff.Text)
+	assert.Contains(t, diff.Text, "model.onnx: LFS pointer, LFS object added 5.0 MiB (rule: git-lfs pointer)\n")
+	assert.Contains(t, diff.Text, "old.bin: binary, deleted 4 B\n")
+	assert.False(t, strings.Contains(diff.Text, "sha256:"))
+}
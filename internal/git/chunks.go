@@ -0,0 +1,104 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const truncatedMarker = "\n… truncated"
+
+type DiffChunks struct {
+	Chunks   []string
+	Redacted string
+}
+
+func (d DiffChunks) Size() int {
+	size := 0
+	for _, chunk := range d.Chunks {
+		size += len(chunk)
+	}
+	return size
+}
+
+func (c *ExecGitClient) GetDiffChunks(chunkBytes int) (DiffChunks, error) {
+	numstatOutput, err := c.getNumstatOutput()
+	if err != nil || numstatOutput == "" {
+		return DiffChunks{}, err
+	}
+	return c.collectFilteredDiff(numstatOutput, cachedDiffSource("")).chunks(chunkBytes), nil
+}
+
+func (c *ExecGitClient) GetDiffChunksCachedRange(base string, chunkBytes int) (DiffChunks, error) {
+	numstatOutput, err := c.runGitCommand("diff", "--cached", "--numstat", "-z", "-M", "-C", "--no-color", base)
+	if err != nil {
+		return DiffChunks{}, fmt.Errorf("failed to get diff stats: %w", err)
+	}
+	return c.collectFilteredDiff(numstatOutput, cachedDiffSource(base)).chunks(chunkBytes), nil
+}
+
+func (d filteredDiff) chunks(chunkBytes int) DiffChunks {
+	var chunks []string
+	var b strings.Builder
+	for _, e := range d.normal {
+		out, ok := d.diffFile(e)
+		if !ok || out == "" {
+			continue
+		}
+		for _, part := range splitOversizedDiff(e, out, chunkBytes) {
+			if b.Len() > 0 && b.Len()+len(part)+2 > chunkBytes {
+				chunks = append(chunks, b.String())
+				b.Reset()
+			}
+			if b.Len() > 0 {
+				b.WriteString("\n\n")
+			}
+			b.WriteString(part)
+		}
+	}
+	if b.Len() > 0 {
+		chunks = append(chunks, b.String())
+	}
+	return DiffChunks{Chunks: chunks, Redacted: formatRedactedSummary(d.redacted)}
+}
+
+func splitOversizedDiff(e numstatEntry, out string, chunkBytes int) []string {
+	if len(out) <= chunkBytes {
+		return []string{out}
+	}
+	f := splitHunks(e, out)
+	var parts []string
+	var b strings.Builder
+	for _, hunk := range f.hunks {
+		if b.Len() > 0 && b.Len()+len(hunk) > chunkBytes {
+			parts = append(parts, b.String())
+			b.Reset()
+		}
+		if b.Len() == 0 {
+			b.WriteString(f.header)
+		}
+		b.WriteString(hunk)
+	}
+	if b.Len() > 0 {
+		parts = append(parts, b.String())
+	}
+	if len(parts) == 0 {
+		parts = []string{out}
+	}
+	for i, part := range parts {
+		if len(part) > chunkBytes {
+			parts[i] = truncateRunes(part, max(chunkBytes-len(truncatedMarker), 0)) + truncatedMarker
+		}
+	}
+	return parts
+}
+
+func truncateRunes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
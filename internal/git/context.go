@@ -0,0 +1,99 @@
+package git
+
+import "strings"
+
+const functionContextMaxBytes = 4096
+
+type Diff struct {
+	Text    string
+	Context string
+	Secrets []SecretFinding
+}
+
+type contextLevel struct {
+	label string
+	args  []string
+}
+
+var contextLevels = []contextLevel{
+	{label: "3 lines"},
+	{label: "1 line, whitespace ignored", args: []string{"-U1", "-w"}},
+	{label: "0 lines, whitespace ignored", args: []string{"-U0", "-w"}},
+}
+
+func (d filteredDiff) render(limit int) Diff {
+	ranked := rankEntries(d.normal, d.weights)
+	var (
+		files    []fileHunks
+		redacted []classifiedFile
+		level    contextLevel
+	)
+	for _, level = range contextLevels {
+		files, redacted = d.collect(ranked, level.args)
+		if diffSize(files, redacted) <= limit {
+			break
+		}
+	}
+	if len(level.args) == 0 && d.addFunctionContext(files, limit-diffSize(files, redacted)) {
+		level.label = "function context"
+	}
+
+	rendered, omitted := allocateDiffBudget(files, limit)
+	for _, e := range omitted {
+		redacted = append(redacted, classifiedFile{entry: e, category: catLarge})
+	}
+	if summary := formatRedactedSummary(redacted); summary != "" {
+		rendered = append(rendered, summary)
+	}
+	text := strings.Join(rendered, "\n\n")
+	if text == "" {
+		return Diff{}
+	}
+	return Diff{Text: text, Context: level.label, Secrets: sortedFindings(d.secrets)}
+}
+
+func (d filteredDiff) collect(entries []numstatEntry, args []string) ([]fileHunks, []classifiedFile) {
+	var files []fileHunks
+	redacted := append([]classifiedFile{}, d.redacted...)
+	ignoresWhitespace := len(args) > 0
+	for _, e := range entries {
+		out, ok := d.diffFile(e, args...)
+		if !ok {
+			continue
+		}
+		if out == "" {
+			if ignoresWhitespace {
+				redacted = append(redacted, classifiedFile{entry: e, category: catWhitespace})
+			}
+			continue
+		}
+		files = append(files, splitHunks(e, out))
+	}
+	return files, redacted
+}
+
+func (d filteredDiff) addFunctionContext(files []fileHunks, spare int) bool {
+	added := false
+	for i, f := range files {
+		size := f.fullSize()
+		if size > functionContextMaxBytes/2 {
+			continue
+		}
+		out, ok := d.diffFile(f.entry, "--function-context")
+		if !ok || out == "" || out == f.text() || len(out) > functionContextMaxBytes || len(out)-size > spare {
+			continue
+		}
+		spare -= len(out) - size
+		files[i] = splitHunks(f.entry, out)
+		added = true
+	}
+	return added
+}
+
+func diffSize(files []fileHunks, redacted []classifiedFile) int {
+	size := len(formatRedactedSummary(redacted))
+	for _, f := range files {
+		size += f.fullSize() + len("\n\n")
+	}
+	return size
+}
@@ -3,6 +3,7 @@ package git
 import (
 	"fmt"
 	"path"
+	"slices"
 	"strings"
 )
 
@@ -16,6 +17,10 @@ const (
 	catEncrypted
 	catSkipped
 	catLarge
+	catWhitespace
+	catSummaryOnly
+	catGenerated
+	catLFS
 )
 
 func (c fileCategory) label() string {
@@ -32,6 +37,14 @@ func (c fileCategory) label() string {
 		return "skipped"
 	case catLarge:
 		return "large diff omitted"
+	case catWhitespace:
+		return "whitespace-only changes"
+	case catSummaryOnly:
+		return "summary only"
+	case catGenerated:
+		return "generated"
+	case catLFS:
+		return "LFS pointer"
 	}
 	return "normal"
 }
@@ -41,11 +54,44 @@ type numstatEntry struct {
 	deletions string
 	binary    bool
 	path      string
+	oldPath   string
+}
+
+func (e numstatEntry) pathspec() []string {
+	if e.oldPath != "" {
+		return []string{e.oldPath, e.path}
+	}
+	return []string{e.path}
+}
+
+func (e numstatEntry) displayPath() string {
+	if e.oldPath != "" {
+		return e.oldPath + " -> " + e.path
+	}
+	return e.path
 }
 
 type classifiedFile struct {
-	entry    numstatEntry
-	category fileCategory
+	entry     numstatEntry
+	category  fileCategory
+	rule      string
+	generator string
+	detail    string
+}
+
+func (f classifiedFile) label() string {
+	if f.generator != "" {
+		return f.category.label() + " by " + f.generator
+	}
+	return f.category.label()
+}
+
+type RedactionRules struct {
+	Lockfiles   []string
+	Skip        []string
+	SummaryOnly []string
+	Generated   []string
+	Include     []string
 }
 
 var lockfileBasenames = map[string]struct{}{
@@ -68,17 +114,26 @@ var encryptedSuffixes = []string{".ejson", ".age", ".gpg", ".enc"}
 
 func parseNumstatEntries(output string) []numstatEntry {
 	var entries []numstatEntry
-	for _, record := range splitNumstatRecords(output) {
-		parts := strings.SplitN(record, "\t", 3)
+	records := splitNumstatRecords(output)
+	for i := 0; i < len(records); i++ {
+		parts := strings.SplitN(records[i], "\t", 3)
 		if len(parts) != 3 {
 			continue
 		}
-		entries = append(entries, numstatEntry{
+		entry := numstatEntry{
 			additions: parts[0],
 			deletions: parts[1],
 			binary:    parts[0] == "-" && parts[1] == "-",
 			path:      parts[2],
-		})
+		}
+		if entry.path == "" {
+			if i+2 >= len(records) {
+				break
+			}
+			entry.oldPath, entry.path = records[i+1], records[i+2]
+			i += 2
+		}
+		entries = append(entries, entry)
 	}
 	return entries
 }
@@ -94,26 +149,121 @@ func splitNumstatRecords(output string) []string {
 	return records
 }
 
-func classifyEntry(e numstatEntry, attrs map[string]string) fileCategory {
+func classifyEntry(e numstatEntry, attrs map[string]string, rules RedactionRules, head func() string) classifiedFile {
+	f := classifiedFile{entry: e}
+	if _, ok := matchAnyGlob(e.path, rules.Include); ok && !e.binary {
+		return f
+	}
+	f.category, f.rule = classifyByPolicy(e, attrs, rules)
+	if f.category != catNormal {
+		return f
+	}
+	content := ""
+	if head != nil {
+		content = head()
+	}
+	if isLFSPointer(content) {
+		f.category, f.rule = catLFS, "git-lfs pointer"
+		return f
+	}
+	f.category, f.rule, f.generator = detectGenerated(e.path, attrs, func() string { return content })
+	return f
+}
+
+func classifyByPolicy(e numstatEntry, attrs map[string]string, rules RedactionRules) (fileCategory, string) {
+	if cat, rule := rules.matchGlobs(e.path); cat != catNormal {
+		return cat, rule
+	}
 	if v := attrs["yawn"]; v == "skip" || v == "set" || v == "true" {
-		return catSkipped
+		return catSkipped, ".gitattributes yawn=" + v
 	}
-	if attrs["filter"] == "git-crypt" || attrs["diff"] == "git-crypt" {
-		return catGitCrypt
+	for _, attr := range []string{"filter", "diff"} {
+		if attrs[attr] == "git-crypt" {
+			return catGitCrypt, ".gitattributes " + attr + "=git-crypt"
+		}
 	}
+	if attrs["filter"] == "lfs" {
+		return catLFS, ".gitattributes filter=lfs"
+	}
+	return classifyByName(e, rules.Lockfiles)
+}
+
+func classifyByName(e numstatEntry, extraLockfiles []string) (fileCategory, string) {
 	base := path.Base(e.path)
 	if _, ok := lockfileBasenames[base]; ok {
-		return catLockfile
+		return catLockfile, fmt.Sprintf("built-in lockfile %q", base)
+	}
+	if slices.Contains(extraLockfiles, base) {
+		return catLockfile, fmt.Sprintf("redaction.lockfiles %q", base)
 	}
 	for _, suffix := range encryptedSuffixes {
 		if strings.HasSuffix(base, suffix) {
-			return catEncrypted
+			return catEncrypted, fmt.Sprintf("built-in encrypted suffix %q", suffix)
 		}
 	}
 	if e.binary {
-		return catBinary
+		return catBinary, ""
+	}
+	return catNormal, ""
+}
+
+func (r RedactionRules) matchGlobs(p string) (fileCategory, string) {
+	groups := []struct {
+		category fileCategory
+		key      string
+		patterns []string
+	}{
+		{catSkipped, "skip", r.Skip},
+		{catSummaryOnly, "summary_only", r.SummaryOnly},
+		{catGenerated, "generated", r.Generated},
+	}
+	for _, g := range groups {
+		if pattern, ok := matchAnyGlob(p, g.patterns); ok {
+			return g.category, fmt.Sprintf("redaction.%s %q", g.key, pattern)
+		}
+	}
+	return catNormal, ""
+}
+
+func matchAnyGlob(p string, patterns []string) (string, bool) {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, p) {
+			return pattern, true
+		}
 	}
-	return catNormal
+	return "", false
+}
+
+func matchGlob(pattern, p string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(p))
+		return ok
+	}
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	return matchSegments(strings.Split(strings.TrimPrefix(pattern, "/"), "/"), strings.Split(p, "/"))
+}
+
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := len(parts); i >= 0; i-- {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
 }
 
 func formatRedactedSummary(redacted []classifiedFile) string {
@@ -124,12 +274,19 @@ func formatRedactedSummary(redacted []classifiedFile) string {
 	b.WriteString("### Files redacted from diff (summary only):\n")
 	for _, r := range redacted {
 		var stat string
-		if r.entry.binary {
+		switch {
+		case r.detail != "":
+			stat = r.detail
+		case r.entry.binary:
 			stat = "binary"
-		} else {
+		default:
 			stat = fmt.Sprintf("+%s -%s", r.entry.additions, r.entry.deletions)
 		}
-		fmt.Fprintf(&b, "- %s: %s, %s\n", r.entry.path, r.category.label(), stat)
+		fmt.Fprintf(&b, "- %s: %s, %s", r.entry.displayPath(), r.label(), stat)
+		if r.rule != "" {
+			fmt.Fprintf(&b, " (rule: %s)", r.rule)
+		}
+		b.WriteString("\n")
 	}
 	return b.String()
 }
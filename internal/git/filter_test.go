@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseNumstatEntries(t *testing.T) {
@@ -38,6 +39,24 @@ func TestParseNumstatEntries_PathWithNewline(t *testing.T) {
 	assert.Equal(t, "foo.go", entries[1].path)
 }
 
+func TestParseNumstatEntries_Renames(t *testing.T) {
+	output := "0\t0\t\x00old/pkg.go\x00new/pkg.go\x003\t1\tmain.go\x00-\t-\t\x00a.png\x00b.png\x00"
+	entries := parseNumstatEntries(output)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, "new/pkg.go", entries[0].path)
+	assert.Equal(t, "old/pkg.go", entries[0].oldPath)
+	assert.Equal(t, []string{"old/pkg.go", "new/pkg.go"}, entries[0].pathspec())
+	assert.Equal(t, "old/pkg.go -> new/pkg.go", entries[0].displayPath())
+
+	assert.Equal(t, "main.go", entries[1].path)
+	assert.Empty(t, entries[1].oldPath)
+
+	assert.True(t, entries[2].binary)
+	assert.Equal(t, "a.png", entries[2].oldPath)
+	assert.Equal(t, "b.png", entries[2].path)
+}
+
 func TestClassifyEntry(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -116,20 +135,66 @@ func TestClassifyEntry(t *testing.T) {
 			attrs: map[string]string{"yawn": "skip"},
 			want:  catSkipped,
 		},
+		{
+			name:  "lfs filter attr",
+			entry: numstatEntry{path: "assets/video.mp4", binary: true},
+			attrs: map[string]string{"filter": "lfs"},
+			want:  catLFS,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyEntry(tt.entry, tt.attrs, RedactionRules{}, nil)
+			assert.Equal(t, tt.want, got.category)
+		})
+	}
+}
+
+func TestClassifyEntry_RedactionRules(t *testing.T) {
+	rules := RedactionRules{
+		Lockfiles:   []string{"deno.lock"},
+		Skip:        []string{"secrets/**"},
+		SummaryOnly: []string{"db/schema.sql", "*.snap"},
+		Generated:   []string{"api/**/*.gen.ts"},
+		Include:     []string{"go.sum", "vendor/keep/"},
+	}
+	tests := []struct {
+		name     string
+		entry    numstatEntry
+		attrs    map[string]string
+		want     fileCategory
+		wantRule string
+	}{
+		{"extra lockfile", numstatEntry{path: "web/deno.lock"}, nil, catLockfile, `redaction.lockfiles "deno.lock"`},
+		{"built-in lockfile", numstatEntry{path: "package-lock.json"}, nil, catLockfile, `built-in lockfile "package-lock.json"`},
+		{"skip glob", numstatEntry{path: "secrets/prod/db.yml"}, nil, catSkipped, `redaction.skip "secrets/**"`},
+		{"summary-only path", numstatEntry{path: "db/schema.sql"}, nil, catSummaryOnly, `redaction.summary_only "db/schema.sql"`},
+		{"summary-only basename glob", numstatEntry{path: "ui/__snapshots__/app.snap"}, nil, catSummaryOnly, `redaction.summary_only "*.snap"`},
+		{"generated glob", numstatEntry{path: "api/v1/client.gen.ts"}, nil, catGenerated, `redaction.generated "api/**/*.gen.ts"`},
+		{"generated glob at depth zero", numstatEntry{path: "api/client.gen.ts"}, nil, catGenerated, `redaction.generated "api/**/*.gen.ts"`},
+		{"include beats lockfile", numstatEntry{path: "go.sum"}, nil, catNormal, ""},
+		{"include directory beats attrs", numstatEntry{path: "vendor/keep/a.go"}, map[string]string{"yawn": "skip"}, catNormal, ""},
+		{"include cannot show binary", numstatEntry{path: "go.sum", binary: true}, nil, catLockfile, `built-in lockfile "go.sum"`},
+		{"gitattributes rule", numstatEntry{path: "a.txt"}, map[string]string{"yawn": "skip"}, catSkipped, ".gitattributes yawn=skip"},
+		{"git-crypt rule", numstatEntry{path: "a.txt"}, map[string]string{"diff": "git-crypt"}, catGitCrypt, ".gitattributes diff=git-crypt"},
+		{"unmatched", numstatEntry{path: "secrets.go"}, nil, catNormal, ""},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := classifyEntry(tt.entry, tt.attrs)
-			assert.Equal(t, tt.want, got)
+			got := classifyEntry(tt.entry, tt.attrs, rules, nil)
+			assert.Equal(t, tt.want, got.category)
+			assert.Equal(t, tt.wantRule, got.rule)
 		})
 	}
 }
 
 func TestFormatRedactedSummary(t *testing.T) {
 	redacted := []classifiedFile{
-		{entry: numstatEntry{path: "package-lock.json", additions: "120", deletions: "45"}, category: catLockfile},
+		{entry: numstatEntry{path: "package-lock.json", additions: "120", deletions: "45"}, category: catLockfile, rule: `built-in lockfile "package-lock.json"`},
 		{entry: numstatEntry{path: "img.png", binary: true}, category: catBinary},
+		{entry: numstatEntry{path: "logo.png", binary: true}, category: catBinary, detail: "1.0 KiB -> 2.0 KiB, PNG 64x64 -> PNG 128x128"},
 		{entry: numstatEntry{path: "secrets.ejson", additions: "2", deletions: "1"}, category: catEncrypted},
 		{entry: numstatEntry{path: "vault/key.txt", additions: "3", deletions: "0"}, category: catGitCrypt},
 		{entry: numstatEntry{path: "big.txt", additions: "99999", deletions: "0"}, category: catLarge},
@@ -137,8 +202,9 @@ func TestFormatRedactedSummary(t *testing.T) {
 	got := formatRedactedSummary(redacted)
 
 	assert.True(t, strings.HasPrefix(got, "### Files redacted from diff"))
-	assert.Contains(t, got, "package-lock.json: lockfile, +120 -45")
+	assert.Contains(t, got, "package-lock.json: lockfile, +120 -45 (rule: built-in lockfile \"package-lock.json\")\n")
 	assert.Contains(t, got, "img.png: binary, binary")
+	assert.Contains(t, got, "logo.png: binary, 1.0 KiB -> 2.0 KiB, PNG 64x64 -> PNG 128x128\n")
 	assert.Contains(t, got, "secrets.ejson: encrypted, +2 -1")
 	assert.Contains(t, got, "vault/key.txt: git-crypt, +3 -0")
 	assert.Contains(t, got, "big.txt: large diff omitted, +99999 -0")
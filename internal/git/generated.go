@@ -0,0 +1,94 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	generatedHeadBytes = 8192
+	minifiedLineBytes  = 500
+)
+
+var generatedHeader = regexp.MustCompile(`(?m)^\s*(?://|#|/\*|\*|--|;|<!--)?\s*(?:Code generated (.*)DO NOT EDIT|@generated\b)`)
+
+var generatedByPattern = regexp.MustCompile(`\bby\s+"?([A-Za-z0-9_./@-]+)`)
+
+var generatedNamePatterns = []struct {
+	pattern   string
+	generator string
+}{
+	{"*_grpc.pb.go", "protoc-gen-go-grpc"},
+	{"*.pb.gw.go", "protoc-gen-grpc-gateway"},
+	{"*.pb.go", "protoc-gen-go"},
+	{"*_pb2_grpc.py", "grpcio-tools"},
+	{"*_pb2.py", "protoc"},
+	{"*_generated.go", ""},
+	{"*.gen.go", ""},
+	{"*_mock.go", ""},
+	{"mock_*.go", ""},
+	{"*.min.js", ""},
+	{"*.min.css", ""},
+}
+
+var minifiedExtensions = []string{".js", ".mjs", ".cjs", ".css"}
+
+func detectGenerated(p string, attrs map[string]string, head func() string) (fileCategory, string, string) {
+	content := ""
+	if head != nil {
+		content = head()
+	}
+	header := generatedHeader.FindStringSubmatch(content)
+	pattern, generator := matchGeneratedName(p)
+	if header != nil {
+		if name := generatorName(header[1]); name != "" {
+			generator = name
+		}
+	}
+	switch v := attrs["linguist-generated"]; {
+	case v == "set" || v == "true":
+		return catGenerated, ".gitattributes linguist-generated", generator
+	case header != nil:
+		return catGenerated, "generated-code header", generator
+	case pattern != "":
+		return catGenerated, fmt.Sprintf("built-in pattern %q", pattern), generator
+	case strings.HasPrefix(p, "vendor/") || strings.Contains(p, "/vendor/"):
+		return catGenerated, "vendored under vendor/", ""
+	case hasAnySuffix(p, minifiedExtensions) && hasLongLine(content, minifiedLineBytes):
+		return catGenerated, fmt.Sprintf("minified, lines over %d bytes", minifiedLineBytes), ""
+	}
+	return catNormal, "", ""
+}
+
+func matchGeneratedName(p string) (string, string) {
+	for _, known := range generatedNamePatterns {
+		if matchGlob(known.pattern, p) {
+			return known.pattern, known.generator
+		}
+	}
+	return "", ""
+}
+
+func isGeneratedName(p string) bool {
+	pattern, _ := matchGeneratedName(p)
+	return pattern != ""
+}
+
+func generatorName(headerText string) string {
+	match := generatedByPattern.FindStringSubmatch(headerText)
+	if match == nil {
+		return ""
+	}
+	return path.Base(strings.TrimRight(match[1], ".,;:"))
+}
+
+func hasLongLine(content string, limit int) bool {
+	for line := range strings.SplitSeq(content, "\n") {
+		if len(line) >= limit {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectGenerated(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		attrs         map[string]string
+		head          string
+		wantCategory  fileCategory
+		wantRule      string
+		wantGenerator string
+	}{
+		{"go header names generator", "api/client.go", nil, "// Code generated by sqlc. DO NOT EDIT.\n// versions:\npackage api\n", catGenerated, "generated-code header", "sqlc"},
+		{"quoted generator command", "pill_string.go", nil, "// Code generated by \"stringer -type=Pill\"; DO NOT EDIT.\n", catGenerated, "generated-code header", "stringer"},
+		{"generator import path", "graph/generated.go", nil, "// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.\n", catGenerated, "generated-code header", "gqlgen"},
+		{"header without generator", "schema.py", nil, "# Code generated from schema.json. DO NOT EDIT.\n", catGenerated, "generated-code header", ""},
+		{"at-generated marker", "Schema.java", nil, "/*\n * @generated SignedSource<<abc>>\n */\n", catGenerated, "generated-code header", ""},
+		{"protobuf by name", "proto/user.pb.go", nil, "package proto\n", catGenerated, `built-in pattern "*.pb.go"`, "protoc-gen-go"},
+		{"protobuf header beats pattern name", "proto/user_grpc.pb.go", nil, "// Code generated by protoc-gen-go-grpc. DO NOT EDIT.\n", catGenerated, "generated-code header", "protoc-gen-go-grpc"},
+		{"mock by name", "store/store_mock.go", nil, "package store\n", catGenerated, `built-in pattern "*_mock.go"`, ""},
+		{"linguist-generated attr", "docs/api.md", map[string]string{"linguist-generated": "set"}, "", catGenerated, ".gitattributes linguist-generated", ""},
+		{"vendored", "vendor/github.com/pkg/errors/errors.go", nil, "package errors\n", catGenerated, "vendored under vendor/", ""},
+		{"nested vendor", "tools/vendor/x/y.go", nil, "package y\n", catGenerated, "vendored under vendor/", ""},
+		{"minified js", "static/app.js", nil, strings.Repeat("a=1;", 200), catGenerated, "minified, lines over 500 bytes", ""},
+		{"long go line is not minified", "main.go", nil, strings.Repeat("a", 600), catNormal, "", ""},
+		{"header in code body does not count", "filter.go", nil, "package git\n\nvar s = \"// Code generated by x. DO NOT EDIT.\"\n", catNormal, "", ""},
+		{"regular source", "main.go", nil, "package main\n", catNormal, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			category, rule, generator := detectGenerated(tt.path, tt.attrs, func() string { return tt.head })
+			assert.Equal(t, tt.wantCategory, category)
+			assert.Equal(t, tt.wantRule, rule)
+			assert.Equal(t, tt.wantGenerator, generator)
+		})
+	}
+}
+
+func TestClassifyEntry_IncludeBeatsGenerated(t *testing.T) {
+	head := func() string { return "// Code generated by mockgen. DO NOT EDIT.\n" }
+	f := classifyEntry(numstatEntry{path: "store_mock.go"}, nil, RedactionRules{Include: []string{"*_mock.go"}}, head)
+	assert.Equal(t, catNormal, f.category)
+
+	f = classifyEntry(numstatEntry{path: "store_mock.go", additions: "40", deletions: "2"}, nil, RedactionRules{}, head)
+	assert.Equal(t, "- store_mock.go: generated by mockgen, +40 -2 (rule: generated-code header)\n",
+		strings.TrimPrefix(formatRedactedSummary([]classifiedFile{f}), "### Files redacted from diff (summary only):\n"))
+}
+
+func TestExecGitClient_GetDiffSummarizesGeneratedFiles(t *testing.T) {
+	repo := newTestRepo(t)
+	writeTestFile(t, repo, "README.md", "# test\n")
+	runTestGit(t, repo, "add", ".")
+	runTestGit(t, repo, "commit", "-m", "initial")
+
+	writeTestFile(t, repo, "user.pb.go", "// Code generated by protoc-gen-go. DO NOT EDIT.\npackage api\n\nvar user = 1\n")
+	writeTestFile(t, repo, "api.go", "package api\n\nvar mine = 1\n")
+	runTestGit(t, repo, "add", ".")
+
+	client := &ExecGitClient{RepoPath: repo}
+	diff, err := client.GetDiff()
+	require.NoError(t, err)
+
+	assert.Contains(t, diff.Text, "+var mine = 1")
+	assert.NotContains(t, diff.Text, "var user = 1")
+	assert.Contains(t, diff.Text, "- user.pb.go: generated by protoc-gen-go, +4 -0 (rule: generated-code header)")
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strconv"
@@ -27,10 +28,32 @@ type GitClient interface {
 	HasStagedChanges() (bool, error)
 	HasUnstagedChanges() (bool, error)
 	HasAnyChanges() (bool, error)
-	GetDiff() (string, error)
+	GetDiff() (Diff, error)
+	GetDiffChunks(chunkBytes int) (DiffChunks, error)
 	StageChanges() error
+	ListUnstagedFiles() ([]ChangedFile, error)
+	StagePaths(paths []string) error
+	FindStagingViolations() ([]StagingViolation, error)
+	IgnorePaths(paths []string, target string) error
+	SnapshotIndex() (IndexSnapshot, error)
+	ReadTree(tree string) error
+	ListSplitFiles(s IndexSnapshot) ([]SplitFile, error)
+	GetSplitDiff(s IndexSnapshot) (string, error)
+	CommitSelection(s IndexSnapshot, selections []SplitSelection, message string) error
+	GetEditor() (string, error)
 	Commit(message string) error
 	AmendCommit(message string) error
+	GetAmendBase() (string, error)
+	IsPushed(rev string) (bool, error)
+	ListRewordCommits(revRange string) ([]RewordCommit, error)
+	GetCommitDiff(hash string) (Diff, error)
+	GetCommitDiffChunks(hash string, chunkBytes int) (DiffChunks, error)
+	RewordCommits(commits []RewordCommit, messages map[string]string) error
+	RecordOperation(op Operation) error
+	LastOperation() (Operation, bool, error)
+	DropLastOperation() error
+	ResolveRef(ref string) (string, error)
+	MoveHead(from, to string) error
 	Push(command string) (string, error)
 	HasRemotes() (bool, error)
 	GetCurrentBranch() (string, error)
@@ -41,12 +64,14 @@ type GitClient interface {
 	FindBranchBase(branch string) (string, error)
 	FindBranchBaseRef(branch string) (string, error)
 	GetCommitCountRange(base string) (int, error)
-	GetDiffRange(base string) (string, error)
-	GetDiffCachedRange(base string) (string, error)
+	GetDiffRange(base string) (Diff, error)
+	GetDiffCachedRange(base string) (Diff, error)
+	GetDiffChunksCachedRange(base string, chunkBytes int) (DiffChunks, error)
 	GetDiffNumStatRange(base string) (additions int, deletions int, err error)
 	GetDiffNumStatCachedRange(base string) (additions int, deletions int, err error)
 	ResetSoft(commit string) error
-	Stash() error
+	CreateBackupRef(branch string) (string, error)
+	Stash() (string, error)
 	StashPop() error
 	GetUnpushedCommits() ([]string, error)
 	GetRemoteOnlyCommits() ([]string, error)
@@ -57,7 +82,11 @@ type GitClient interface {
 }
 
 type ExecGitClient struct {
-	RepoPath string
+	RepoPath    string
+	RankWeights RankWeights
+	Redaction   RedactionRules
+	Secrets     *SecretScanner
+	StageGuard  *StagingGuard
 }
 
 func NewExecGitClient() (*ExecGitClient, error) {
@@ -105,9 +134,13 @@ func (c *ExecGitClient) runGitCommand(args ...string) (string, error) {
 }
 
 func (c *ExecGitClient) runGitCommandContext(ctx context.Context, args ...string) (string, error) {
+	return c.runGitCommandEnv(ctx, nil, args...)
+}
+
+func (c *ExecGitClient) runGitCommandEnv(ctx context.Context, env []string, args ...string) (string, error) {
 	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = c.RepoPath
-	cmd.Env = append(os.Environ(), "GIT_PAGER=cat")
+	cmd.Env = append(append(os.Environ(), "GIT_PAGER=cat"), env...)
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -166,16 +199,16 @@ func (c *ExecGitClient) HasAnyChanges() (bool, error) {
 	return c.HasStagedChanges()
 }
 
-func (c *ExecGitClient) GetDiff() (string, error) {
+func (c *ExecGitClient) GetDiff() (Diff, error) {
 	numstatOutput, err := c.getNumstatOutput()
 	if err != nil || numstatOutput == "" {
-		return "", err
+		return Diff{}, err
 	}
-	return c.buildFilteredDiff(numstatOutput, []string{"diff", "--cached", "--no-color"}), nil
+	return c.buildFilteredDiff(numstatOutput, cachedDiffSource("")), nil
 }
 
 func (c *ExecGitClient) getNumstatOutput() (string, error) {
-	numstatOutput, err := c.runGitCommand("diff", "--cached", "--numstat", "-z", "--no-renames", "--no-color")
+	numstatOutput, err := c.runGitCommand("diff", "--cached", "--numstat", "-z", "-M", "-C", "--no-color")
 	if err != nil {
 		if gitErr, ok := err.(*GitError); ok && gitErr.Output != "" {
 			return gitErr.Output, nil
@@ -199,75 +232,136 @@ func (c *ExecGitClient) checkAttrs(attrs []string, files []string) (map[string]m
 	return parseCheckAttrOutput(out), nil
 }
 
-func (c *ExecGitClient) buildFilteredDiff(numstatOutput string, diffBaseArgs []string) string {
+type diffSource struct {
+	args   []string
+	oldRev string
+	newRev string
+}
+
+func cachedDiffSource(base string) diffSource {
+	if base == "" {
+		return diffSource{args: []string{"diff", "--cached", "--no-color"}, oldRev: "HEAD"}
+	}
+	return diffSource{args: []string{"diff", "--cached", "--no-color", base}, oldRev: base}
+}
+
+type filteredDiff struct {
+	normal   []numstatEntry
+	redacted []classifiedFile
+	weights  RankWeights
+	secrets  map[SecretFinding]struct{}
+	diffFile func(e numstatEntry, extraArgs ...string) (string, bool)
+}
+
+func (c *ExecGitClient) collectFilteredDiff(numstatOutput string, src diffSource) filteredDiff {
 	entries := parseNumstatEntries(numstatOutput)
 	if len(entries) == 0 {
-		return ""
+		return filteredDiff{}
 	}
 	paths := make([]string, len(entries))
 	for i, e := range entries {
 		paths[i] = e.path
 	}
-	attrs, _ := c.checkAttrs([]string{"filter", "diff", "yawn"}, paths)
+	attrs, _ := c.checkAttrs([]string{"filter", "diff", "yawn", "linguist-generated"}, paths)
 
-	var normal []numstatEntry
-	var redacted []classifiedFile
-	for _, e := range entries {
-		cat := classifyEntry(e, attrs[e.path])
-		if cat == catNormal {
-			normal = append(normal, e)
-			continue
+	d := filteredDiff{weights: c.RankWeights, secrets: map[SecretFinding]struct{}{}}
+	alternatives := map[string]alternativeDiff{}
+	d.diffFile = func(e numstatEntry, extraArgs ...string) (string, bool) {
+		out, ok := c.diffEntry(src, e, extraArgs)
+		if !ok || out == "" {
+			return out, ok
 		}
-		redacted = append(redacted, classifiedFile{entry: e, category: cat})
-	}
-
-	var b strings.Builder
-	for i, e := range normal {
-		args := append([]string{}, diffBaseArgs...)
-		args = append(args, "--")
-		args = append(args, e.path)
-		out, err := c.runGitCommand(args...)
-		if err != nil {
-			if gitErr, ok := err.(*GitError); ok && gitErr.Output != "" {
-				out = gitErr.Output
-			} else {
-				continue
-			}
+		alt, cached := alternatives[e.path]
+		if !cached {
+			alt = c.renderAlternative(src, e)
+			alternatives[e.path] = alt
 		}
-		if b.Len() > 0 && out != "" {
-			out = "\n\n" + out
+		if alt.text != "" && (alt.replace || len(alt.text) < len(out)) {
+			return c.maskSecrets(d.secrets, e.path, alt.text, true), true
 		}
-		if writeLimited(&b, out, MaxDiffBytes) {
+		return c.maskSecrets(d.secrets, e.path, out, false), true
+	}
+	for _, e := range entries {
+		f := classifyEntry(e, attrs[e.path], c.Redaction, func() string {
+			return c.readBlob(src.newRev+":"+e.path, generatedHeadBytes)
+		})
+		if f.category == catNormal {
+			d.normal = append(d.normal, e)
 			continue
 		}
-		for _, omitted := range normal[i:] {
-			redacted = append(redacted, classifiedFile{entry: omitted, category: catLarge})
+		if f.category == catBinary || f.category == catLFS {
+			f.detail = c.describeBinary(src, f)
 		}
-		break
+		d.redacted = append(d.redacted, f)
+	}
+	return d
+}
+
+type alternativeDiff struct {
+	text    string
+	replace bool
+}
+
+func (c *ExecGitClient) renderAlternative(src diffSource, e numstatEntry) alternativeDiff {
+	if isNotebook(e.path) {
+		return alternativeDiff{text: c.notebookDiff(src, e), replace: true}
+	}
+	return alternativeDiff{text: c.structuredSummary(src, e)}
+}
+
+func (c *ExecGitClient) diffEntry(src diffSource, e numstatEntry, extraArgs []string) (string, bool) {
+	args := append([]string{}, src.args...)
+	args = append(args, extraArgs...)
+	if e.oldPath != "" {
+		args = append(args, "-M", "-C", "--diff-filter=RC")
 	}
-	if summary := formatRedactedSummary(redacted); summary != "" {
-		if b.Len() > 0 {
-			b.WriteString("\n\n")
+	args = append(args, "--")
+	args = append(args, e.pathspec()...)
+	out, err := c.runGitCommand(args...)
+	if err != nil {
+		gitErr, ok := err.(*GitError)
+		if !ok || gitErr.Output == "" {
+			return "", false
 		}
-		b.WriteString(summary)
+		return gitErr.Output, true
 	}
-	return b.String()
+	return out, true
 }
 
-func writeLimited(b *strings.Builder, s string, limit int) bool {
-	if s == "" {
-		return true
+func (c *ExecGitClient) maskSecrets(found map[SecretFinding]struct{}, path, out string, summary bool) string {
+	if c.Secrets == nil {
+		return out
+	}
+	var findings []SecretFinding
+	if summary {
+		out, findings = c.Secrets.maskSummary(path, out)
+	} else {
+		out, findings = c.Secrets.maskDiff(path, out)
 	}
-	remaining := limit - b.Len()
-	if remaining <= 0 {
-		return false
+	for _, f := range findings {
+		found[f] = struct{}{}
 	}
-	if len(s) <= remaining {
-		b.WriteString(s)
-		return true
+	return out
+}
+
+func (c *ExecGitClient) buildFilteredDiff(numstatOutput string, src diffSource) Diff {
+	return c.collectFilteredDiff(numstatOutput, src).render(MaxDiffBytes)
+}
+
+func (c *ExecGitClient) readBlob(spec string, limit int) string {
+	cmd := exec.Command("git", "cat-file", "blob", spec)
+	cmd.Dir = c.RepoPath
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return ""
+	}
+	if err := cmd.Start(); err != nil {
+		return ""
 	}
-	b.WriteString(s[:remaining])
-	return false
+	content, _ := io.ReadAll(io.LimitReader(stdout, int64(limit)))
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+	return string(content)
 }
 
 func (c *ExecGitClient) StageChanges() error {
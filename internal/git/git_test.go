@@ -9,8 +9,10 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetDiffNumStatSummary(t *testing.T) {
@@ -386,9 +388,179 @@ func TestExecGitClient_GetDiffSummarizesOverBudgetFiles(t *testing.T) {
 	diff, err := client.GetDiff()
 
 	assert.NoError(t, err)
-	assert.Contains(t, diff, "large diff omitted")
-	assert.Contains(t, diff, "big.txt")
-	assert.Contains(t, diff, "small.txt")
+	assert.Contains(t, diff.Text, "+++ b/big.txt\n(+120000 -0, some hunk bodies omitted)\n@@ -0,0 +1,120000 @@")
+	assert.Contains(t, diff.Text, "+++ b/small.txt\n@@ -0,0 +1 @@\n+small")
+	assert.LessOrEqual(t, len(diff.Text), MaxDiffBytes)
+	assert.Equal(t, "0 lines, whitespace ignored", diff.Context)
+}
+
+func TestExecGitClient_GetDiffAddsFunctionContextForSmallFiles(t *testing.T) {
+	repo := newTestRepo(t)
+	writeTestFile(t, repo, "main.go", "package main\n\nfunc main() {\n\ta := 1\n\tb := 2\n\tc := 3\n\td := 4\n\te := 5\n\tprintln(a, b, c, d, e)\n}\n")
+	runTestGit(t, repo, "add", "main.go")
+	runTestGit(t, repo, "commit", "-m", "add main")
+	writeTestFile(t, repo, "main.go", "package main\n\nfunc main() {\n\ta := 1\n\tb := 2\n\tc := 3\n\td := 4\n\te := 5\n\tprintln(a, b, c, d, e, \"done\")\n}\n")
+	runTestGit(t, repo, "add", "main.go")
+
+	diff, err := (&ExecGitClient{RepoPath: repo}).GetDiff()
+
+	require.NoError(t, err)
+	assert.Equal(t, "function context", diff.Context)
+	assert.Contains(t, diff.Text, "func main() {\n \ta := 1\n")
+}
+
+func TestFilteredDiffRenderStepsDownContext(t *testing.T) {
+	entries := []numstatEntry{
+		{path: "a.go", additions: "1", deletions: "1"},
+		{path: "fmt.go", additions: "1", deletions: "1"},
+	}
+	diffs := map[string]map[string]string{
+		"": {
+			"a.go":   "diff --git a/a.go b/a.go\n@@ -1,7 +1,7 @@\n first context line\n second context line\n ctx\n-old\n+new\n ctx\n second to last context line\n last context line",
+			"fmt.go": "diff --git a/fmt.go b/fmt.go\n@@ -1 +1 @@\n-x  = 1\n+x = 1",
+		},
+		"-U1": {
+			"a.go": "diff --git a/a.go b/a.go\n@@ -3,3 +3,3 @@\n ctx\n-old\n+new\n ctx",
+		},
+	}
+	d := filteredDiff{normal: entries, diffFile: func(e numstatEntry, extraArgs ...string) (string, bool) {
+		key := ""
+		if len(extraArgs) > 0 && extraArgs[0] != "--function-context" {
+			key = extraArgs[0]
+		}
+		return diffs[key][e.path], true
+	}}
+
+	full := d.render(MaxDiffBytes)
+	assert.Equal(t, "3 lines", full.Context)
+	assert.Contains(t, full.Text, "+x = 1")
+
+	reduced := d.render(160)
+	assert.Equal(t, "1 line, whitespace ignored", reduced.Context)
+	assert.Contains(t, reduced.Text, "@@ -3,3 +3,3 @@\n ctx\n-old\n+new\n ctx")
+	assert.Contains(t, reduced.Text, "- fmt.go: whitespace-only changes, +1 -1")
+}
+
+func TestExecGitClient_GetDiffSummarizesRenames(t *testing.T) {
+	repo := newTestRepo(t)
+	content := strings.Repeat("line of code that stays the same\n", 40)
+	writeTestFile(t, repo, "old.go", content)
+	writeTestFile(t, repo, "edited.go", content+"tail\n")
+	runTestGit(t, repo, "add", ".")
+	runTestGit(t, repo, "commit", "-m", "add files")
+	runTestGit(t, repo, "mv", "old.go", "new.go")
+	runTestGit(t, repo, "mv", "edited.go", "moved.go")
+	writeTestFile(t, repo, "moved.go", content+"changed tail\n")
+	runTestGit(t, repo, "add", ".")
+
+	client := &ExecGitClient{RepoPath: repo}
+	diff, err := client.GetDiff()
+	require.NoError(t, err)
+
+	assert.Contains(t, diff.Text, "old.go -> new.go (similarity 100%)")
+	assert.Regexp(t, `edited.go -> moved.go \(similarity \d+%\)\n@@ `, diff.Text)
+	assert.Contains(t, diff.Text, "+changed tail")
+	assert.NotContains(t, diff.Text, "+line of code that stays the same")
+
+	additions, deletions, err := client.GetDiffNumStatSummary()
+	require.NoError(t, err)
+	assert.Equal(t, 1, additions)
+	assert.Equal(t, 1, deletions)
+}
+
+func TestExecGitClient_GetDiffShowsCopySourceChangesOnce(t *testing.T) {
+	repo := newTestRepo(t)
+	content := strings.Repeat("line of code that stays the same\n", 40)
+	writeTestFile(t, repo, "src.go", content)
+	runTestGit(t, repo, "add", ".")
+	runTestGit(t, repo, "commit", "-m", "add src")
+	writeTestFile(t, repo, "copy.go", content+"copy tail\n")
+	writeTestFile(t, repo, "src.go", content+"source tail\n")
+	runTestGit(t, repo, "add", ".")
+
+	client := &ExecGitClient{RepoPath: repo}
+	diff, err := client.GetDiff()
+	require.NoError(t, err)
+
+	assert.Regexp(t, `src.go -> copy.go \(copy, similarity \d+%\)\n@@ `, diff.Text)
+	assert.Equal(t, 1, strings.Count(diff.Text, "+source tail"))
+	assert.Equal(t, 1, strings.Count(diff.Text, "+copy tail"))
+}
+
+func TestExecGitClient_GetDiffChunksSplitsOversizedFileAtHunks(t *testing.T) {
+	repo := newTestRepo(t)
+	var before, after strings.Builder
+	for i := range 200 {
+		fmt.Fprintf(&before, "line %d\n", i)
+		if i%20 == 10 {
+			fmt.Fprintf(&after, "changed %d\n", i)
+		} else {
+			fmt.Fprintf(&after, "line %d\n", i)
+		}
+	}
+	writeTestFile(t, repo, "big.txt", before.String())
+	runTestGit(t, repo, "add", ".")
+	runTestGit(t, repo, "commit", "-m", "initial")
+	writeTestFile(t, repo, "big.txt", after.String())
+	runTestGit(t, repo, "add", ".")
+
+	client := &ExecGitClient{RepoPath: repo}
+	chunks, err := client.GetDiffChunks(300)
+	require.NoError(t, err)
+
+	require.Greater(t, len(chunks.Chunks), 1)
+	joined := strings.Join(chunks.Chunks, "\n")
+	for _, chunk := range chunks.Chunks {
+		assert.LessOrEqual(t, len(chunk), 300)
+		assert.True(t, strings.HasPrefix(chunk, "diff --git a/big.txt b/big.txt\n"))
+	}
+	for i := 10; i < 200; i += 20 {
+		assert.Contains(t, joined, fmt.Sprintf("+changed %d\n", i))
+	}
+	assert.NotContains(t, joined, "truncated")
+}
+
+func TestSplitOversizedDiffTruncatesGiantHunk(t *testing.T) {
+	diff := "diff --git a/a.txt b/a.txt\n@@ -1 +1 @@\n+" + strings.Repeat("ж", 200) + "\n"
+
+	parts := splitOversizedDiff(numstatEntry{path: "a.txt"}, diff, 100)
+
+	require.Len(t, parts, 1)
+	assert.LessOrEqual(t, len(parts[0]), 100)
+	assert.True(t, utf8.ValidString(parts[0]))
+	assert.True(t, strings.HasSuffix(parts[0], "\n… truncated"))
+}
+
+func TestExecGitClient_GetDiffChunksPacksFilesUnderLimit(t *testing.T) {
+	repoPath := t.TempDir()
+	runGitTestCommand(t, repoPath, "init")
+	runGitTestCommand(t, repoPath, "config", "user.email", "test@example.com")
+	runGitTestCommand(t, repoPath, "config", "user.name", "Test User")
+
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(repoPath, name), []byte(strings.Repeat(name+"\n", 100)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "go.sum"), []byte("sum\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGitTestCommand(t, repoPath, "add", ".")
+
+	client := &ExecGitClient{RepoPath: repoPath}
+	chunks, err := client.GetDiffChunks(1200)
+
+	require.NoError(t, err)
+	require.Len(t, chunks.Chunks, 3)
+	for i, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		assert.Contains(t, chunks.Chunks[i], name)
+		assert.LessOrEqual(t, len(chunks.Chunks[i]), 1200)
+	}
+	assert.Contains(t, chunks.Redacted, "go.sum: lockfile")
+
+	chunks, err = client.GetDiffChunks(MaxDiffBytes)
+	require.NoError(t, err)
+	assert.Len(t, chunks.Chunks, 1)
 }
 
 func TestExecGitClient_GetPullRequestURLChecksGHAuthBeforePRLookup(t *testing.T) {
@@ -460,3 +632,31 @@ func runGitTestCommand(t *testing.T, dir string, args ...string) {
 		t.Fatalf("git %v failed: %v\n%s", args, err, output)
 	}
 }
+
+func TestExecGitClient_ListUnstagedFilesAndStagePaths(t *testing.T) {
+	repo := newTestRepo(t)
+	writeTestFile(t, repo, "keep.go", "package keep\n")
+	writeTestFile(t, repo, "gone.go", "package gone\n")
+	runTestGit(t, repo, "add", ".")
+	runTestGit(t, repo, "commit", "-m", "initial")
+
+	writeTestFile(t, repo, "keep.go", "package keep\n\nfunc A() {}\n")
+	require.NoError(t, os.Remove(filepath.Join(repo, "gone.go")))
+	writeTestFile(t, repo, "new.txt", "one\ntwo")
+	writeTestFile(t, repo, "logo.bin", "\x00\x01")
+
+	client := &ExecGitClient{RepoPath: repo}
+	files, err := client.ListUnstagedFiles()
+	require.NoError(t, err)
+	assert.Equal(t, []ChangedFile{
+		{Path: "gone.go", Status: "D", Deletions: 1},
+		{Path: "keep.go", Status: "M", Additions: 2},
+		{Path: "logo.bin", Status: "?", Binary: true},
+		{Path: "new.txt", Status: "?", Additions: 2},
+	}, files)
+	assert.Equal(t, "untracked", files[2].StatusLabel())
+
+	require.NoError(t, client.StagePaths([]string{"gone.go", "new.txt"}))
+	staged := runTestGit(t, repo, "diff", "--cached", "--name-status")
+	assert.Equal(t, "D\tgone.go\nA\tnew.txt", strings.TrimSpace(staged))
+}
@@ -0,0 +1,137 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	IgnoreGitignore = "gitignore"
+	IgnoreExclude   = "exclude"
+)
+
+type StagingGuard struct {
+	Deny         []string
+	MaxFileBytes int64
+}
+
+type StagingViolation struct {
+	Path   string
+	Reason string
+}
+
+func (v StagingViolation) String() string {
+	return v.Path + " (" + v.Reason + ")"
+}
+
+func (v StagingViolation) Covers(p string) bool {
+	return p == v.Path || (strings.HasSuffix(v.Path, "/") && strings.HasPrefix(p, v.Path))
+}
+
+var guardDirNames = []string{"node_modules", ".venv", "__pycache__", ".terraform"}
+
+var credentialNames = []string{
+	".env", ".env.*",
+	"id_rsa", "id_dsa", "id_ecdsa", "id_ed25519",
+	"*.pem", "*.key", "*.p12", "*.pfx", "*.jks", "*.keystore",
+	".npmrc", ".pypirc", ".netrc", ".pgpass",
+	"credentials.json", "service-account*.json", "*.tfstate",
+}
+
+var credentialTemplateSuffixes = []string{".example", ".sample", ".template", ".dist"}
+
+func (c *ExecGitClient) FindStagingViolations() ([]StagingViolation, error) {
+	if c.StageGuard == nil {
+		return nil, nil
+	}
+	output, err := c.runGitCommand("ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	var violations []StagingViolation
+	seenDirs := map[string]bool{}
+	for _, p := range splitNumstatRecords(output) {
+		if dir, name := guardedDir(p); dir != "" {
+			if !seenDirs[dir] {
+				seenDirs[dir] = true
+				violations = append(violations, StagingViolation{Path: dir, Reason: fmt.Sprintf("built-in deny %q", name+"/")})
+			}
+			continue
+		}
+		if reason := c.StageGuard.check(p, c.fileSize(p)); reason != "" {
+			violations = append(violations, StagingViolation{Path: p, Reason: reason})
+		}
+	}
+	return violations, nil
+}
+
+func (g *StagingGuard) check(p string, size int64) string {
+	if pattern, ok := matchAnyGlob(p, g.Deny); ok {
+		return fmt.Sprintf("stage_guard.deny %q", pattern)
+	}
+	if pattern, ok := matchAnyGlob(p, credentialNames); ok && !hasAnySuffix(p, credentialTemplateSuffixes) {
+		return fmt.Sprintf("credential file %q", pattern)
+	}
+	if g.MaxFileBytes > 0 && size > g.MaxFileBytes {
+		return fmt.Sprintf("%s exceeds the %s limit", formatBytes(size), formatBytes(g.MaxFileBytes))
+	}
+	return ""
+}
+
+func guardedDir(p string) (string, string) {
+	parts := strings.Split(p, "/")
+	for i, part := range parts[:len(parts)-1] {
+		for _, name := range guardDirNames {
+			if part == name {
+				return path.Join(parts[:i+1]...) + "/", name
+			}
+		}
+	}
+	return "", ""
+}
+
+func (c *ExecGitClient) fileSize(p string) int64 {
+	info, err := os.Stat(filepath.Join(c.RepoPath, p))
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+func (c *ExecGitClient) IgnorePaths(paths []string, target string) error {
+	file := filepath.Join(c.RepoPath, ".gitignore")
+	if target == IgnoreExclude {
+		excludePath, err := c.gitPath("info/exclude")
+		if err != nil {
+			return err
+		}
+		file = excludePath
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(file), err)
+		}
+	}
+
+	existing, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	var b strings.Builder
+	if len(existing) > 0 && existing[len(existing)-1] != '\n' {
+		b.WriteString("\n")
+	}
+	for _, p := range paths {
+		b.WriteString("/" + p + "\n")
+	}
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.WriteString(b.String()); err != nil {
+		return fmt.Errorf("failed to update %s: %w", file, err)
+	}
+	return nil
+}
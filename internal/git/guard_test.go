@@ -0,0 +1,81 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStagingGuardCheck(t *testing.T) {
+	guard := &StagingGuard{Deny: []string{"*.sql.gz"}, MaxFileBytes: 1 << 20}
+
+	tests := []struct {
+		path string
+		size int64
+		want string
+	}{
+		{"main.go", 100, ""},
+		{"backup/prod.sql.gz", 10, `stage_guard.deny "*.sql.gz"`},
+		{".env", 10, `credential file ".env"`},
+		{"config/.env.production", 10, `credential file ".env.*"`},
+		{".env.example", 10, ""},
+		{"deploy/id_ed25519", 10, `credential file "id_ed25519"`},
+		{"deploy/id_ed25519.pub", 10, ""},
+		{"certs/server.pem", 10, `credential file "*.pem"`},
+		{"dump.bin", 3 << 20, "3.0 MiB exceeds the 1.0 MiB limit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, guard.check(tt.path, tt.size))
+		})
+	}
+
+	assert.Equal(t, "", (&StagingGuard{}).check("dump.bin", 3<<20))
+}
+
+func TestExecGitClient_FindStagingViolations(t *testing.T) {
+	repo := newTestRepo(t)
+	writeTestFile(t, repo, "README.md", "# repo\n")
+	runTestGit(t, repo, "add", ".")
+	runTestGit(t, repo, "commit", "-m", "initial")
+
+	writeTestFile(t, repo, ".env", "TOKEN=x\n")
+	writeTestFile(t, repo, ".env.example", "TOKEN=\n")
+	writeTestFile(t, repo, "web/node_modules/left-pad/index.js", "module.exports = 1\n")
+	writeTestFile(t, repo, "web/node_modules/left-pad/package.json", "{}\n")
+	writeTestFile(t, repo, "web/app.js", "console.log(1)\n")
+	writeTestFile(t, repo, "dump.bin", strings.Repeat("x", 64))
+
+	client := &ExecGitClient{RepoPath: repo}
+	violations, err := client.FindStagingViolations()
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	client.StageGuard = &StagingGuard{MaxFileBytes: 32}
+	violations, err = client.FindStagingViolations()
+	require.NoError(t, err)
+	assert.Equal(t, []StagingViolation{
+		{Path: ".env", Reason: `credential file ".env"`},
+		{Path: "dump.bin", Reason: "64 B exceeds the 32 B limit"},
+		{Path: "web/node_modules/", Reason: `built-in deny "node_modules/"`},
+	}, violations)
+	assert.Equal(t, ".env (credential file \".env\")", violations[0].String())
+
+	require.NoError(t, client.IgnorePaths([]string{".env", "web/node_modules/"}, IgnoreGitignore))
+	require.NoError(t, client.IgnorePaths([]string{"dump.bin"}, IgnoreExclude))
+
+	gitignore, err := os.ReadFile(filepath.Join(repo, ".gitignore"))
+	require.NoError(t, err)
+	assert.Equal(t, "/.env\n/web/node_modules/\n", string(gitignore))
+	exclude, err := os.ReadFile(filepath.Join(repo, ".git", "info", "exclude"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(exclude), "\n/dump.bin\n"))
+
+	violations, err = client.FindStagingViolations()
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
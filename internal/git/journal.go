@@ -0,0 +1,135 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	OpCommit    = "commit"
+	OpAmend     = "amend"
+	OpSquash    = "squash"
+	OpSplit     = "split"
+	OpReword    = "reword"
+	OpForcePush = "force-push"
+	OpRemoteURL = "remote-url"
+)
+
+type Operation struct {
+	Kind      string    `json:"kind"`
+	Time      time.Time `json:"time"`
+	Branch    string    `json:"branch,omitempty"`
+	Remote    string    `json:"remote,omitempty"`
+	Before    string    `json:"before,omitempty"`
+	After     string    `json:"after,omitempty"`
+	BackupRef string    `json:"backup_ref,omitempty"`
+	StashID   string    `json:"stash_id,omitempty"`
+}
+
+func (c *ExecGitClient) gitPath(name string) (string, error) {
+	p, err := c.runGitCommand("rev-parse", "--git-path", name)
+	if err != nil {
+		return "", fmt.Errorf("failed to locate .git/%s: %w", name, err)
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(c.RepoPath, p)
+	}
+	return p, nil
+}
+
+func (c *ExecGitClient) readJournal() (string, []string, error) {
+	file, err := c.gitPath("yawn/journal.jsonl")
+	if err != nil {
+		return "", nil, err
+	}
+	content, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return "", nil, fmt.Errorf("failed to read the yawn journal: %w", err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return file, lines, nil
+}
+
+func (c *ExecGitClient) RecordOperation(op Operation) error {
+	if op.Time.IsZero() {
+		op.Time = time.Now()
+	}
+	line, err := json.Marshal(op)
+	if err != nil {
+		return fmt.Errorf("failed to encode the %s operation: %w", op.Kind, err)
+	}
+	file, err := c.gitPath("yawn/journal.jsonl")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(file), err)
+	}
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open the yawn journal: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write the yawn journal: %w", err)
+	}
+	return nil
+}
+
+func (c *ExecGitClient) LastOperation() (Operation, bool, error) {
+	_, lines, err := c.readJournal()
+	if err != nil || len(lines) == 0 {
+		return Operation{}, false, err
+	}
+	var op Operation
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &op); err != nil {
+		return Operation{}, false, fmt.Errorf("failed to parse the last yawn journal entry: %w", err)
+	}
+	return op, true, nil
+}
+
+func (c *ExecGitClient) DropLastOperation() error {
+	file, lines, err := c.readJournal()
+	if err != nil || len(lines) == 0 {
+		return err
+	}
+	content := ""
+	if len(lines) > 1 {
+		content = strings.Join(lines[:len(lines)-1], "\n") + "\n"
+	}
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to update the yawn journal: %w", err)
+	}
+	return nil
+}
+
+func (c *ExecGitClient) ResolveRef(ref string) (string, error) {
+	output, err := c.runGitCommand("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		if gitErr, ok := err.(*GitError); ok && gitErr.ExitCode == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return output, nil
+}
+
+func (c *ExecGitClient) MoveHead(from, to string) error {
+	args := []string{"update-ref", "-m", "yawn undo", "HEAD", to, from}
+	if to == "" {
+		args = []string{"update-ref", "-m", "yawn undo", "-d", "HEAD", from}
+	}
+	if _, err := c.runGitCommand(args...); err != nil {
+		return fmt.Errorf("failed to move HEAD: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalRecordsAndDropsOperations(t *testing.T) {
+	repo := newTestRepo(t)
+	client := &ExecGitClient{RepoPath: repo}
+
+	_, ok, err := client.LastOperation()
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, client.RecordOperation(Operation{Kind: OpCommit, Branch: "master", Before: "a", After: "b"}))
+	require.NoError(t, client.RecordOperation(Operation{Kind: OpSquash, Branch: "master", Before: "b", After: "c", BackupRef: "refs/yawn/backup/master/1", StashID: "s"}))
+
+	op, ok, err := client.LastOperation()
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, OpSquash, op.Kind)
+	assert.Equal(t, "refs/yawn/backup/master/1", op.BackupRef)
+	assert.Equal(t, "s", op.StashID)
+	assert.False(t, op.Time.IsZero())
+	assert.FileExists(t, repo+"/.git/yawn/journal.jsonl")
+
+	require.NoError(t, client.DropLastOperation())
+	op, ok, err = client.LastOperation()
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, OpCommit, op.Kind)
+
+	require.NoError(t, client.DropLastOperation())
+	_, ok, err = client.LastOperation()
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestMoveHeadComparesCurrentValue(t *testing.T) {
+	repo := newTestRepo(t)
+	client := &ExecGitClient{RepoPath: repo}
+	root := runTestGit(t, repo, "rev-parse", "HEAD")
+	writeTestFile(t, repo, "main.go", "package main\n")
+	runTestGit(t, repo, "add", "main.go")
+	runTestGit(t, repo, "commit", "-m", "add main")
+	head := runTestGit(t, repo, "rev-parse", "HEAD")
+
+	assert.Error(t, client.MoveHead(root, root))
+	require.NoError(t, client.MoveHead(head, root))
+	assert.Equal(t, root, runTestGit(t, repo, "rev-parse", "HEAD"))
+	assert.Equal(t, "A  main.go", runTestGit(t, repo, "status", "--short"))
+
+	require.NoError(t, client.MoveHead(root, ""))
+	resolved, err := client.ResolveRef("HEAD")
+	require.NoError(t, err)
+	assert.Empty(t, resolved)
+}
@@ -4,10 +4,32 @@ type MockGitClient struct {
 	MockHasStagedChanges          func() (bool, error)
 	MockHasUnstagedChanges        func() (bool, error)
 	MockHasAnyChanges             func() (bool, error)
-	MockGetDiff                   func() (string, error)
+	MockGetDiff                   func() (Diff, error)
+	MockGetDiffChunks             func(chunkBytes int) (DiffChunks, error)
 	MockStageChanges              func() error
+	MockListUnstagedFiles         func() ([]ChangedFile, error)
+	MockStagePaths                func(paths []string) error
+	MockFindStagingViolations     func() ([]StagingViolation, error)
+	MockIgnorePaths               func(paths []string, target string) error
+	MockSnapshotIndex             func() (IndexSnapshot, error)
+	MockReadTree                  func(tree string) error
+	MockListSplitFiles            func(s IndexSnapshot) ([]SplitFile, error)
+	MockGetSplitDiff              func(s IndexSnapshot) (string, error)
+	MockCommitSelection           func(s IndexSnapshot, selections []SplitSelection, message string) error
+	MockGetEditor                 func() (string, error)
 	MockCommit                    func(message string) error
 	MockAmendCommit               func(message string) error
+	MockGetAmendBase              func() (string, error)
+	MockIsPushed                  func(rev string) (bool, error)
+	MockListRewordCommits         func(revRange string) ([]RewordCommit, error)
+	MockGetCommitDiff             func(hash string) (Diff, error)
+	MockGetCommitDiffChunks       func(hash string, chunkBytes int) (DiffChunks, error)
+	MockRewordCommits             func(commits []RewordCommit, messages map[string]string) error
+	MockRecordOperation           func(op Operation) error
+	MockLastOperation             func() (Operation, bool, error)
+	MockDropLastOperation         func() error
+	MockResolveRef                func(ref string) (string, error)
+	MockMoveHead                  func(from, to string) error
 	MockPush                      func(command string) (string, error)
 	MockHasRemotes                func() (bool, error)
 	MockGetCurrentBranch          func() (string, error)
@@ -18,12 +40,14 @@ type MockGitClient struct {
 	MockFindBranchBase            func(branch string) (string, error)
 	MockFindBranchBaseRef         func(branch string) (string, error)
 	MockGetCommitCountRange       func(base string) (int, error)
-	MockGetDiffRange              func(base string) (string, error)
-	MockGetDiffCachedRange        func(base string) (string, error)
+	MockGetDiffRange              func(base string) (Diff, error)
+	MockGetDiffCachedRange        func(base string) (Diff, error)
+	MockGetDiffChunksCachedRange  func(base string, chunkBytes int) (DiffChunks, error)
 	MockGetDiffNumStatRange       func(base string) (additions int, deletions int, err error)
 	MockGetDiffNumStatCachedRange func(base string) (additions int, deletions int, err error)
 	MockResetSoft                 func(commit string) error
-	MockStash                     func() error
+	MockCreateBackupRef           func(branch string) (string, error)
+	MockStash                     func() (string, error)
 	MockStashPop                  func() error
 	MockGetUnpushedCommits        func() ([]string, error)
 	MockGetRemoteOnlyCommits      func() ([]string, error)
@@ -54,11 +78,18 @@ func (m *MockGitClient) HasAnyChanges() (bool, error) {
 	return false, nil
 }
 
-func (m *MockGitClient) GetDiff() (string, error) {
+func (m *MockGitClient) GetDiff() (Diff, error) {
 	if m.MockGetDiff != nil {
 		return m.MockGetDiff()
 	}
-	return "diff --git a/file.txt b/file.txt\n--- a/file.txt\n+++ b/file.txt\n@@ -1 +1 @@\n-old\n+new", nil
+	return Diff{Text: "diff --git a/file.txt b/file.txt\n--- a/file.txt\n+++ b/file.txt\n@@ -1 +1 @@\n-old\n+new"}, nil
+}
+
+func (m *MockGitClient) GetDiffChunks(chunkBytes int) (DiffChunks, error) {
+	if m.MockGetDiffChunks != nil {
+		return m.MockGetDiffChunks(chunkBytes)
+	}
+	return DiffChunks{}, nil
 }
 
 func (m *MockGitClient) StageChanges() error {
@@ -68,6 +99,76 @@ func (m *MockGitClient) StageChanges() error {
 	return nil
 }
 
+func (m *MockGitClient) ListUnstagedFiles() ([]ChangedFile, error) {
+	if m.MockListUnstagedFiles != nil {
+		return m.MockListUnstagedFiles()
+	}
+	return nil, nil
+}
+
+func (m *MockGitClient) StagePaths(paths []string) error {
+	if m.MockStagePaths != nil {
+		return m.MockStagePaths(paths)
+	}
+	return nil
+}
+
+func (m *MockGitClient) FindStagingViolations() ([]StagingViolation, error) {
+	if m.MockFindStagingViolations != nil {
+		return m.MockFindStagingViolations()
+	}
+	return nil, nil
+}
+
+func (m *MockGitClient) IgnorePaths(paths []string, target string) error {
+	if m.MockIgnorePaths != nil {
+		return m.MockIgnorePaths(paths, target)
+	}
+	return nil
+}
+
+func (m *MockGitClient) SnapshotIndex() (IndexSnapshot, error) {
+	if m.MockSnapshotIndex != nil {
+		return m.MockSnapshotIndex()
+	}
+	return IndexSnapshot{Head: "HEAD", Tree: "tree"}, nil
+}
+
+func (m *MockGitClient) ReadTree(tree string) error {
+	if m.MockReadTree != nil {
+		return m.MockReadTree(tree)
+	}
+	return nil
+}
+
+func (m *MockGitClient) ListSplitFiles(s IndexSnapshot) ([]SplitFile, error) {
+	if m.MockListSplitFiles != nil {
+		return m.MockListSplitFiles(s)
+	}
+	return nil, nil
+}
+
+func (m *MockGitClient) GetSplitDiff(s IndexSnapshot) (string, error) {
+	if m.MockGetSplitDiff != nil {
+		return m.MockGetSplitDiff(s)
+	}
+	return "", nil
+}
+
+func (m *MockGitClient) CommitSelection(s IndexSnapshot, selections []SplitSelection, message string) error {
+	if m.MockCommitSelection != nil {
+		return m.MockCommitSelection(s, selections, message)
+	}
+	return nil
+}
+
+func (m *MockGitClient) GetEditor() (string, error) {
+	if m.MockGetEditor != nil {
+		return m.MockGetEditor()
+	}
+	return "vi", nil
+}
+
 func (m *MockGitClient) Commit(message string) error {
 	if m.MockCommit != nil {
 		return m.MockCommit(message)
@@ -82,6 +183,83 @@ func (m *MockGitClient) AmendCommit(message string) error {
 	return nil
 }
 
+func (m *MockGitClient) GetAmendBase() (string, error) {
+	if m.MockGetAmendBase != nil {
+		return m.MockGetAmendBase()
+	}
+	return "HEAD^", nil
+}
+
+func (m *MockGitClient) IsPushed(rev string) (bool, error) {
+	if m.MockIsPushed != nil {
+		return m.MockIsPushed(rev)
+	}
+	return false, nil
+}
+
+func (m *MockGitClient) ListRewordCommits(revRange string) ([]RewordCommit, error) {
+	if m.MockListRewordCommits != nil {
+		return m.MockListRewordCommits(revRange)
+	}
+	return nil, nil
+}
+
+func (m *MockGitClient) GetCommitDiff(hash string) (Diff, error) {
+	if m.MockGetCommitDiff != nil {
+		return m.MockGetCommitDiff(hash)
+	}
+	return Diff{}, nil
+}
+
+func (m *MockGitClient) GetCommitDiffChunks(hash string, chunkBytes int) (DiffChunks, error) {
+	if m.MockGetCommitDiffChunks != nil {
+		return m.MockGetCommitDiffChunks(hash, chunkBytes)
+	}
+	return DiffChunks{}, nil
+}
+
+func (m *MockGitClient) RewordCommits(commits []RewordCommit, messages map[string]string) error {
+	if m.MockRewordCommits != nil {
+		return m.MockRewordCommits(commits, messages)
+	}
+	return nil
+}
+
+func (m *MockGitClient) RecordOperation(op Operation) error {
+	if m.MockRecordOperation != nil {
+		return m.MockRecordOperation(op)
+	}
+	return nil
+}
+
+func (m *MockGitClient) LastOperation() (Operation, bool, error) {
+	if m.MockLastOperation != nil {
+		return m.MockLastOperation()
+	}
+	return Operation{}, false, nil
+}
+
+func (m *MockGitClient) DropLastOperation() error {
+	if m.MockDropLastOperation != nil {
+		return m.MockDropLastOperation()
+	}
+	return nil
+}
+
+func (m *MockGitClient) ResolveRef(ref string) (string, error) {
+	if m.MockResolveRef != nil {
+		return m.MockResolveRef(ref)
+	}
+	return "", nil
+}
+
+func (m *MockGitClient) MoveHead(from, to string) error {
+	if m.MockMoveHead != nil {
+		return m.MockMoveHead(from, to)
+	}
+	return nil
+}
+
 func (m *MockGitClient) Push(command string) (string, error) {
 	if m.MockPush != nil {
 		return m.MockPush(command)
@@ -152,18 +330,25 @@ func (m *MockGitClient) GetCommitCountRange(base string) (int, error) {
 	return 3, nil
 }
 
-func (m *MockGitClient) GetDiffRange(base string) (string, error) {
+func (m *MockGitClient) GetDiffRange(base string) (Diff, error) {
 	if m.MockGetDiffRange != nil {
 		return m.MockGetDiffRange(base)
 	}
-	return "diff --git a/file.txt b/file.txt\n--- a/file.txt\n+++ b/file.txt\n@@ -1 +1 @@\n-old\n+new", nil
+	return Diff{Text: "diff --git a/file.txt b/file.txt\n--- a/file.txt\n+++ b/file.txt\n@@ -1 +1 @@\n-old\n+new"}, nil
 }
 
-func (m *MockGitClient) GetDiffCachedRange(base string) (string, error) {
+func (m *MockGitClient) GetDiffCachedRange(base string) (Diff, error) {
 	if m.MockGetDiffCachedRange != nil {
 		return m.MockGetDiffCachedRange(base)
 	}
-	return "diff --git a/file.txt b/file.txt\n--- a/file.txt\n+++ b/file.txt\n@@ -1 +1 @@\n-old\n+new", nil
+	return Diff{Text: "diff --git a/file.txt b/file.txt\n--- a/file.txt\n+++ b/file.txt\n@@ -1 +1 @@\n-old\n+new"}, nil
+}
+
+func (m *MockGitClient) GetDiffChunksCachedRange(base string, chunkBytes int) (DiffChunks, error) {
+	if m.MockGetDiffChunksCachedRange != nil {
+		return m.MockGetDiffChunksCachedRange(base, chunkBytes)
+	}
+	return DiffChunks{}, nil
 }
 
 func (m *MockGitClient) GetDiffNumStatRange(base string) (int, int, error) {
@@ -180,6 +365,13 @@ func (m *MockGitClient) GetDiffNumStatCachedRange(base string) (int, int, error)
 	return 0, 0, nil
 }
 
+func (m *MockGitClient) CreateBackupRef(branch string) (string, error) {
+	if m.MockCreateBackupRef != nil {
+		return m.MockCreateBackupRef(branch)
+	}
+	return "refs/yawn/backup/" + branch + "/20240101-000000", nil
+}
+
 func (m *MockGitClient) ResetSoft(commit string) error {
 	if m.MockResetSoft != nil {
 		return m.MockResetSoft(commit)
@@ -187,11 +379,11 @@ func (m *MockGitClient) ResetSoft(commit string) error {
 	return nil
 }
 
-func (m *MockGitClient) Stash() error {
+func (m *MockGitClient) Stash() (string, error) {
 	if m.MockStash != nil {
 		return m.MockStash()
 	}
-	return nil
+	return "stash", nil
 }
 
 func (m *MockGitClient) StashPop() error {
@@ -0,0 +1,190 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	notebookMaxBytes = 64 << 20
+	lcsMaxCells      = 1 << 20
+)
+
+type notebookCell struct {
+	kind   string
+	source string
+}
+
+func (c notebookCell) equal(other notebookCell) bool {
+	return c.kind == other.kind && c.source == other.source
+}
+
+func isNotebook(p string) bool {
+	return strings.HasSuffix(strings.ToLower(p), ".ipynb")
+}
+
+func parseNotebook(content string) ([]notebookCell, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, nil
+	}
+	var doc struct {
+		Cells []struct {
+			CellType string          `json:"cell_type"`
+			Source   json.RawMessage `json:"source"`
+		} `json:"cells"`
+	}
+	if err := json.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, err
+	}
+	cells := make([]notebookCell, len(doc.Cells))
+	for i, cell := range doc.Cells {
+		var lines []string
+		if err := json.Unmarshal(cell.Source, &lines); err != nil {
+			var source string
+			if err := json.Unmarshal(cell.Source, &source); err != nil && len(cell.Source) > 0 {
+				return nil, fmt.Errorf("invalid source in cell %d: %w", i, err)
+			}
+			lines = []string{source}
+		}
+		cells[i] = notebookCell{kind: cell.CellType, source: strings.Join(lines, "")}
+	}
+	return cells, nil
+}
+
+func (c *ExecGitClient) notebookDiff(src diffSource, e numstatEntry) string {
+	oldPath := e.path
+	if e.oldPath != "" {
+		oldPath = e.oldPath
+	}
+	before, err := parseNotebook(c.readBlob(src.oldRev+":"+oldPath, notebookMaxBytes))
+	if err != nil {
+		return ""
+	}
+	after, err := parseNotebook(c.readBlob(src.newRev+":"+e.path, notebookMaxBytes))
+	if err != nil {
+		return ""
+	}
+	return formatNotebookDiff(e, before, after)
+}
+
+func formatNotebookDiff(e numstatEntry, before, after []notebookCell) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (notebook, outputs and execution counts stripped)\n", e.displayPath())
+	changed := false
+	oldIndex, newIndex := 0, 0
+	for _, pair := range matchCells(before, after) {
+		removed, added := before[oldIndex:pair[0]], after[newIndex:pair[1]]
+		for k := range max(len(removed), len(added)) {
+			switch {
+			case k >= len(removed):
+				writeNotebookCell(&b, newIndex+k, "added", nil, &added[k])
+			case k >= len(added):
+				writeNotebookCell(&b, oldIndex+k, "removed", &removed[k], nil)
+			case removed[k].kind != added[k].kind:
+				writeNotebookCell(&b, oldIndex+k, "removed", &removed[k], nil)
+				writeNotebookCell(&b, newIndex+k, "added", nil, &added[k])
+			default:
+				writeNotebookCell(&b, newIndex+k, "modified", &removed[k], &added[k])
+			}
+			changed = true
+		}
+		oldIndex, newIndex = pair[0]+1, pair[1]+1
+	}
+	if !changed {
+		b.WriteString("no cell source changes")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func writeNotebookCell(b *strings.Builder, index int, change string, before, after *notebookCell) {
+	var oldLines, newLines []string
+	kind := ""
+	if before != nil {
+		oldLines, kind = splitSourceLines(before.source), before.kind
+	}
+	if after != nil {
+		newLines, kind = splitSourceLines(after.source), after.kind
+	}
+	fmt.Fprintf(b, "## cell %d [%s] %s\n", index+1, kind, change)
+	for _, line := range diffLines(oldLines, newLines) {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
+
+func splitSourceLines(source string) []string {
+	if source == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(source, "\n"), "\n")
+}
+
+func matchCells(before, after []notebookCell) [][2]int {
+	if len(before)*len(after) > lcsMaxCells {
+		return [][2]int{{len(before), len(after)}}
+	}
+	equal := func(i, j int) bool { return before[i].equal(after[j]) }
+	pairs := walkLCS(lcsTable(len(before), len(after), equal), len(before), len(after), equal)
+	return append(pairs, [2]int{len(before), len(after)})
+}
+
+func diffLines(before, after []string) []string {
+	var pairs [][2]int
+	if len(before)*len(after) <= lcsMaxCells {
+		equal := func(i, j int) bool { return before[i] == after[j] }
+		pairs = walkLCS(lcsTable(len(before), len(after), equal), len(before), len(after), equal)
+	}
+	var out []string
+	i, j := 0, 0
+	for _, pair := range pairs {
+		for ; i < pair[0]; i++ {
+			out = append(out, "-"+before[i])
+		}
+		for ; j < pair[1]; j++ {
+			out = append(out, "+"+after[j])
+		}
+		out = append(out, " "+after[j])
+		i, j = i+1, j+1
+	}
+	for ; i < len(before); i++ {
+		out = append(out, "-"+before[i])
+	}
+	for ; j < len(after); j++ {
+		out = append(out, "+"+after[j])
+	}
+	return out
+}
+
+func lcsTable(n, m int, equal func(i, j int) bool) [][]int {
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if equal(i, j) {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+	return table
+}
+
+func walkLCS(table [][]int, n, m int, equal func(i, j int) bool) [][2]int {
+	var pairs [][2]int
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case equal(i, j):
+			pairs = append(pairs, [2]int{i, j})
+			i, j = i+1, j+1
+		case table[i+1][j] >= table[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
@@ -0,0 +1,110 @@
+package git
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCell struct {
+	kind   string
+	source string
+}
+
+func testNotebook(t *testing.T, cells ...testCell) string {
+	t.Helper()
+	var raw []map[string]any
+	for i, c := range cells {
+		cell := map[string]any{
+			"cell_type": c.kind,
+			"metadata":  map[string]any{},
+			"source":    strings.SplitAfter(c.source, "\n"),
+		}
+		if c.kind == "code" {
+			cell["execution_count"] = i + 1
+			cell["outputs"] = []any{map[string]any{
+				"output_type": "display_data",
+				"data":        map[string]any{"image/png": strings.Repeat("iVBORw0KGgo", 2000)},
+			}}
+		}
+		raw = append(raw, cell)
+	}
+	encoded, err := json.MarshalIndent(map[string]any{"cells": raw, "nbformat": 4, "nbformat_minor": 5, "metadata": map[string]any{}}, "", " ")
+	require.NoError(t, err)
+	return string(encoded)
+}
+
+func TestParseNotebook(t *testing.T) {
+	cells, err := parseNotebook(`{"cells": [{"cell_type": "code", "source": ["a = 1\n", "b = 2"]}, {"cell_type": "markdown", "source": "# Title"}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, []notebookCell{{kind: "code", source: "a = 1\nb = 2"}, {kind: "markdown", source: "# Title"}}, cells)
+
+	_, err = parseNotebook("not json")
+	assert.Error(t, err)
+}
+
+func TestFormatNotebookDiff(t *testing.T) {
+	before := []notebookCell{
+		{kind: "markdown", source: "# Analysis"},
+		{kind: "code", source: "import pandas as pd\ndf = pd.read_csv(\"a.csv\")"},
+		{kind: "code", source: "print(df)"},
+		{kind: "code", source: "df.plot()"},
+	}
+	after := []notebookCell{
+		{kind: "markdown", source: "# Analysis"},
+		{kind: "code", source: "import pandas as pd\ndf = pd.read_csv(\"b.csv\")"},
+		{kind: "markdown", source: "## Results"},
+		{kind: "code", source: "df.plot()"},
+	}
+
+	got := formatNotebookDiff(numstatEntry{path: "analysis.ipynb"}, before, after)
+
+	assert.Equal(t, strings.Join([]string{
+		"analysis.ipynb (notebook, outputs and execution counts stripped)",
+		"## cell 2 [code] modified",
+		" import pandas as pd",
+		"-df = pd.read_csv(\"a.csv\")",
+		"+df = pd.read_csv(\"b.csv\")",
+		"## cell 3 [code] removed",
+		"-print(df)",
+		"## cell 3 [markdown] added",
+		"+## Results",
+	}, "\n"), got)
+
+	unchanged := formatNotebookDiff(numstatEntry{path: "a.ipynb"}, before, before)
+	assert.True(t, strings.HasSuffix(unchanged, "\nno cell source changes"))
+}
+
+func TestExecGitClient_GetDiffRendersNotebooks(t *testing.T) {
+	repo := newTestRepo(t)
+	writeTestFile(t, repo, "analysis.ipynb", testNotebook(t,
+		testCell{"markdown", "# Analysis"},
+		testCell{"code", "x = load()\nx.describe()"},
+	))
+	runTestGit(t, repo, "add", ".")
+	runTestGit(t, repo, "commit", "-m", "initial")
+
+	writeTestFile(t, repo, "analysis.ipynb", testNotebook(t,
+		testCell{"markdown", "# Analysis"},
+		testCell{"code", "x = load()\nx.describe()\nx.plot()"},
+		testCell{"code", "x.save()"},
+	))
+	runTestGit(t, repo, "add", ".")
+
+	client := &ExecGitClient{RepoPath: repo}
+	diff, err := client.GetDiff()
+	require.NoError(t, err)
+
+	assert.Equal(t, strings.Join([]string{
+		"analysis.ipynb (notebook, outputs and execution counts stripped)",
+		"## cell 2 [code] modified",
+		" x = load()",
+		" x.describe()",
+		"+x.plot()",
+		"## cell 3 [code] added",
+		"+x.save()",
+	}, "\n"), diff.Text)
+}
@@ -0,0 +1,257 @@
+package git
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type RankWeights struct {
+	Source    float64
+	Test      float64
+	Docs      float64
+	Fixture   float64
+	Generated float64
+	Churn     float64
+}
+
+type fileKind int
+
+const (
+	kindSource fileKind = iota
+	kindTest
+	kindDocs
+	kindFixture
+	kindGenerated
+)
+
+var (
+	docsExtensions      = []string{".md", ".mdx", ".rst", ".adoc", ".txt"}
+	docsBasenames       = []string{"README", "CHANGELOG", "LICENSE", "CONTRIBUTING", "NOTICE", "AUTHORS"}
+	docsDirs            = []string{"docs", "doc", "documentation"}
+	fixtureDirs         = []string{"testdata", "fixtures", "fixture", "__fixtures__", "__snapshots__", "snapshots", "golden"}
+	fixtureExtensions   = []string{".snap", ".golden"}
+	testDirs            = []string{"test", "tests", "__tests__", "spec", "specs", "e2e"}
+	testSuffixes        = []string{"_test.go", "_test.py", "_spec.rb", "_test.rb", "Test.java", "Tests.cs"}
+	testInfixes         = []string{".test.", ".spec."}
+	generatedPathPrefix = []string{"vendor/", "node_modules/", "dist/", "gen/", "generated/"}
+)
+
+func classifyKind(p string) fileKind {
+	base := path.Base(p)
+	dirs := strings.Split(path.Dir(p), "/")
+	switch {
+	case isGeneratedName(p) || hasAnyPrefix(p, generatedPathPrefix):
+		return kindGenerated
+	case hasAnySuffix(base, fixtureExtensions) || containsAny(dirs, fixtureDirs):
+		return kindFixture
+	case hasAnySuffix(base, testSuffixes) || strings.HasPrefix(base, "test_") || containsInfix(base, testInfixes) || containsAny(dirs, testDirs):
+		return kindTest
+	case hasAnySuffix(strings.ToLower(base), docsExtensions) || hasAnyPrefix(strings.ToUpper(base), docsBasenames) || containsAny(dirs, docsDirs):
+		return kindDocs
+	}
+	return kindSource
+}
+
+func (w RankWeights) score(e numstatEntry) float64 {
+	var weight float64
+	switch classifyKind(e.path) {
+	case kindSource:
+		weight = w.Source
+	case kindTest:
+		weight = w.Test
+	case kindDocs:
+		weight = w.Docs
+	case kindFixture:
+		weight = w.Fixture
+	case kindGenerated:
+		weight = w.Generated
+	}
+	additions, _ := strconv.Atoi(e.additions)
+	deletions, _ := strconv.Atoi(e.deletions)
+	return weight + w.Churn*math.Log2(1+float64(additions+deletions))
+}
+
+func rankEntries(entries []numstatEntry, w RankWeights) []numstatEntry {
+	ranked := append([]numstatEntry{}, entries...)
+	scores := make(map[string]float64, len(ranked))
+	for _, e := range ranked {
+		scores[e.path] = w.score(e)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i].path] > scores[ranked[j].path]
+	})
+	return ranked
+}
+
+type fileHunks struct {
+	entry  numstatEntry
+	header string
+	hunks  []string
+}
+
+func splitHunks(e numstatEntry, diff string) fileHunks {
+	f := fileHunks{entry: e}
+	start := -1
+	for offset := 0; offset < len(diff); {
+		end := strings.IndexByte(diff[offset:], '\n')
+		if end < 0 {
+			end = len(diff)
+		} else {
+			end += offset + 1
+		}
+		if strings.HasPrefix(diff[offset:], "@@") {
+			if start < 0 {
+				f.header = diff[:offset]
+			} else {
+				f.hunks = append(f.hunks, diff[start:offset])
+			}
+			start = offset
+		}
+		offset = end
+	}
+	if start < 0 {
+		f.header = compactRenameHeader(e, diff)
+		return f
+	}
+	f.header = compactRenameHeader(e, f.header)
+	f.hunks = append(f.hunks, diff[start:])
+	return f
+}
+
+func compactRenameHeader(e numstatEntry, header string) string {
+	if e.oldPath == "" {
+		return header
+	}
+	similarity, kind := "", ""
+	for _, line := range strings.Split(header, "\n") {
+		switch {
+		case strings.HasPrefix(line, "similarity index "):
+			similarity = strings.TrimPrefix(line, "similarity index ")
+		case strings.HasPrefix(line, "copy from "):
+			kind = "copy, "
+		}
+	}
+	if similarity == "" {
+		return header
+	}
+	line := fmt.Sprintf("%s -> %s (%ssimilarity %s)", e.oldPath, e.path, kind, similarity)
+	if strings.HasSuffix(header, "\n") {
+		line += "\n"
+	}
+	return line
+}
+
+func (f fileHunks) hunkHeader(i int) string {
+	header, _, _ := strings.Cut(f.hunks[i], "\n")
+	return header + "\n"
+}
+
+func (f fileHunks) statLine() string {
+	return fmt.Sprintf("(+%s -%s, some hunk bodies omitted)\n", f.entry.additions, f.entry.deletions)
+}
+
+func (f fileHunks) skeletonSize() int {
+	size := len(f.header)
+	if len(f.hunks) > 0 {
+		size += len(f.statLine())
+	}
+	for i := range f.hunks {
+		size += len(f.hunkHeader(i))
+	}
+	return size
+}
+
+func (f fileHunks) fullSize() int {
+	size := len(f.header)
+	for _, hunk := range f.hunks {
+		size += len(hunk)
+	}
+	return size
+}
+
+func (f fileHunks) text() string {
+	return f.header + strings.Join(f.hunks, "")
+}
+
+func (f fileHunks) render(budget int) (string, int) {
+	if extra := f.fullSize() - f.skeletonSize(); extra <= budget {
+		return strings.TrimRight(f.text(), "\n"), max(extra, 0)
+	}
+	var b strings.Builder
+	b.WriteString(f.header)
+	b.WriteString(f.statLine())
+	used := 0
+	for i, hunk := range f.hunks {
+		header := f.hunkHeader(i)
+		if body := len(hunk) - len(header); body <= budget-used {
+			b.WriteString(hunk)
+			used += body
+			continue
+		}
+		b.WriteString(header)
+	}
+	return strings.TrimRight(b.String(), "\n"), used
+}
+
+func allocateDiffBudget(files []fileHunks, limit int) (rendered []string, omitted []numstatEntry) {
+	const separator = len("\n\n")
+	var kept []fileHunks
+	reserved := 0
+	for _, f := range files {
+		cost := f.skeletonSize() + separator
+		if reserved+cost > limit {
+			omitted = append(omitted, f.entry)
+			continue
+		}
+		reserved += cost
+		kept = append(kept, f)
+	}
+	remaining := limit - reserved
+	for _, f := range kept {
+		out, used := f.render(remaining)
+		remaining -= used
+		rendered = append(rendered, out)
+	}
+	return rendered, omitted
+}
+
+func hasAnySuffix(s string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInfix(s string, infixes []string) bool {
+	for _, infix := range infixes {
+		if strings.Contains(s, infix) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(values, candidates []string) bool {
+	for _, value := range values {
+		if slices.Contains(candidates, value) {
+			return true
+		}
+	}
+	return false
+}
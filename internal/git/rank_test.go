@@ -0,0 +1,100 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRankWeights = RankWeights{Source: 100, Test: 60, Docs: 30, Fixture: 20, Generated: 10, Churn: 3}
+
+func TestClassifyKind(t *testing.T) {
+	tests := []struct {
+		path string
+		want fileKind
+	}{
+		{"internal/app/app.go", kindSource},
+		{"internal/app/app_test.go", kindTest},
+		{"web/src/button.spec.tsx", kindTest},
+		{"tests/test_cli.py", kindTest},
+		{"README.md", kindDocs},
+		{"docs/configuration.md", kindDocs},
+		{"internal/git/testdata/diff.golden", kindFixture},
+		{"__snapshots__/view.snap", kindFixture},
+		{"api/service.pb.go", kindGenerated},
+		{"vendor/github.com/pkg/errors/errors.go", kindGenerated},
+		{"static/app.min.js", kindGenerated},
+		{"internal/db/query.gen.go", kindGenerated},
+		{"internal/mocks/mock_client.go", kindGenerated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyKind(tt.path))
+		})
+	}
+}
+
+func TestRankEntries(t *testing.T) {
+	entries := []numstatEntry{
+		{path: "README.md", additions: "500", deletions: "0"},
+		{path: "api/service.pb.go", additions: "2000", deletions: "1500"},
+		{path: "app/app_test.go", additions: "40", deletions: "2"},
+		{path: "app/app.go", additions: "3", deletions: "1"},
+		{path: "app/big.go", additions: "300", deletions: "100"},
+	}
+
+	var paths []string
+	for _, e := range rankEntries(entries, testRankWeights) {
+		paths = append(paths, e.path)
+	}
+
+	assert.Equal(t, []string{"app/big.go", "app/app.go", "app/app_test.go", "README.md", "api/service.pb.go"}, paths)
+}
+
+func TestRankEntriesKeepsOrderWithoutWeights(t *testing.T) {
+	entries := []numstatEntry{{path: "b.md"}, {path: "a.go"}}
+	assert.Equal(t, entries, rankEntries(entries, RankWeights{}))
+}
+
+func TestSplitHunks(t *testing.T) {
+	diff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1,2 +1,2 @@ func a()\n-old\n+new\n@@ -10 +10 @@\n-x\n+y"
+	f := splitHunks(numstatEntry{path: "a.go", additions: "2", deletions: "2"}, diff)
+
+	assert.Equal(t, "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n", f.header)
+	require.Len(t, f.hunks, 2)
+	assert.Equal(t, "@@ -1,2 +1,2 @@ func a()\n", f.hunkHeader(0))
+	assert.Equal(t, "@@ -10 +10 @@\n-x\n+y", f.hunks[1])
+	assert.Equal(t, len(diff), f.fullSize())
+}
+
+func TestAllocateDiffBudgetKeepsHunkHeadersForEveryFile(t *testing.T) {
+	body := strings.Repeat("+line\n", 200)
+	files := []fileHunks{
+		splitHunks(numstatEntry{path: "main.go", additions: "200", deletions: "0"}, "diff --git a/main.go b/main.go\n@@ -0,0 +1,200 @@\n"+body),
+		splitHunks(numstatEntry{path: "main_test.go", additions: "200", deletions: "0"}, "diff --git a/main_test.go b/main_test.go\n@@ -0,0 +1,200 @@\n"+body),
+		splitHunks(numstatEntry{path: "README.md", additions: "200", deletions: "0"}, "diff --git a/README.md b/README.md\n@@ -0,0 +1,200 @@\n"+body),
+	}
+
+	rendered, omitted := allocateDiffBudget(files, 1600)
+
+	assert.Empty(t, omitted)
+	require.Len(t, rendered, 3)
+	assert.Equal(t, strings.TrimRight(files[0].header+files[0].hunks[0], "\n"), rendered[0])
+	assert.Equal(t, "diff --git a/main_test.go b/main_test.go\n(+200 -0, some hunk bodies omitted)\n@@ -0,0 +1,200 @@", rendered[1])
+	assert.Equal(t, "diff --git a/README.md b/README.md\n(+200 -0, some hunk bodies omitted)\n@@ -0,0 +1,200 @@", rendered[2])
+}
+
+func TestAllocateDiffBudgetOmitsFilesWhenHeadersDoNotFit(t *testing.T) {
+	files := []fileHunks{
+		splitHunks(numstatEntry{path: "a.go", additions: "1", deletions: "0"}, "diff --git a/a.go b/a.go\n@@ -0,0 +1 @@\n+a"),
+		splitHunks(numstatEntry{path: "b.go", additions: "1", deletions: "0"}, "diff --git a/b.go b/b.go\n@@ -0,0 +1 @@\n+b"),
+	}
+
+	rendered, omitted := allocateDiffBudget(files, 80)
+
+	assert.Len(t, rendered, 1)
+	assert.Equal(t, []numstatEntry{files[1].entry}, omitted)
+}
@@ -0,0 +1,139 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type RewordCommit struct {
+	Hash    string
+	Message string
+}
+
+func (c *ExecGitClient) ListRewordCommits(revRange string) ([]RewordCommit, error) {
+	output, err := c.runGitCommand("rev-list", "--reverse", "--topo-order", revRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits in %s: %w", revRange, err)
+	}
+	if output == "" {
+		return nil, nil
+	}
+	hashes := strings.Split(output, "\n")
+	head, err := c.runGitCommand("rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	outside, err := c.runGitCommand("rev-list", revRange, "--not", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits in %s: %w", revRange, err)
+	}
+	if hashes[len(hashes)-1] != head || outside != "" {
+		return nil, fmt.Errorf("%s must end at HEAD, e.g. origin/main..HEAD", revRange)
+	}
+	if merges, err := c.runGitCommand("rev-list", "--merges", revRange); err != nil || merges != "" {
+		return nil, fmt.Errorf("%s contains merge commits, which cannot be reworded", revRange)
+	}
+
+	commits := make([]RewordCommit, len(hashes))
+	for i, hash := range hashes {
+		message, err := c.runGitCommand("log", "-1", "--format=%B", hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the message of %s: %w", hash, err)
+		}
+		commits[i] = RewordCommit{Hash: hash, Message: message}
+	}
+	return commits, nil
+}
+
+func (c *ExecGitClient) GetCommitDiff(hash string) (Diff, error) {
+	numstatOutput, src, err := c.commitDiffInput(hash)
+	if err != nil {
+		return Diff{}, err
+	}
+	return c.buildFilteredDiff(numstatOutput, src), nil
+}
+
+func (c *ExecGitClient) GetCommitDiffChunks(hash string, chunkBytes int) (DiffChunks, error) {
+	numstatOutput, src, err := c.commitDiffInput(hash)
+	if err != nil {
+		return DiffChunks{}, err
+	}
+	return c.collectFilteredDiff(numstatOutput, src).chunks(chunkBytes), nil
+}
+
+func (c *ExecGitClient) commitDiffInput(hash string) (string, diffSource, error) {
+	parent, err := c.parentOrEmptyTree(hash)
+	if err != nil {
+		return "", diffSource{}, err
+	}
+	numstatOutput, err := c.runGitCommand("diff", "--numstat", "-z", "-M", "-C", "--no-color", parent, hash)
+	if err != nil {
+		return "", diffSource{}, fmt.Errorf("failed to get diff stats of %s: %w", hash, err)
+	}
+	return numstatOutput, diffSource{args: []string{"diff", "--no-color", parent, hash}, oldRev: parent, newRev: hash}, nil
+}
+
+func (c *ExecGitClient) RewordCommits(commits []RewordCommit, messages map[string]string) error {
+	if len(commits) == 0 {
+		return nil
+	}
+	rewritten := map[string]string{}
+	for _, commit := range commits {
+		newHash, err := c.rewriteCommit(commit, messages, rewritten)
+		if err != nil {
+			return err
+		}
+		if newHash != "" {
+			rewritten[commit.Hash] = newHash
+		}
+	}
+
+	oldHead := commits[len(commits)-1].Hash
+	newHead, ok := rewritten[oldHead]
+	if !ok {
+		return nil
+	}
+	if _, err := c.runGitCommand("update-ref", "-m", "yawn reword", "HEAD", newHead, oldHead); err != nil {
+		return fmt.Errorf("failed to move HEAD to the reworded commits: %w", err)
+	}
+	return nil
+}
+
+func (c *ExecGitClient) rewriteCommit(commit RewordCommit, messages, rewritten map[string]string) (string, error) {
+	output, err := c.runGitCommand("show", "-s", "--date=raw", "--format=%T%x00%P%x00%an%x00%ae%x00%ad%x00%cn%x00%ce%x00%cd", commit.Hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit %s: %w", commit.Hash, err)
+	}
+	fields := strings.Split(output, "\x00")
+	if len(fields) != 8 {
+		return "", fmt.Errorf("unexpected metadata for commit %s", commit.Hash)
+	}
+
+	message, reworded := messages[commit.Hash]
+	args := []string{"commit-tree", fields[0]}
+	parentChanged := false
+	for _, parent := range strings.Fields(fields[1]) {
+		if newParent, ok := rewritten[parent]; ok {
+			parent, parentChanged = newParent, true
+		}
+		args = append(args, "-p", parent)
+	}
+	if !reworded && !parentChanged {
+		return "", nil
+	}
+	if !reworded {
+		message = commit.Message
+	}
+	args = append(args, "-m", message)
+
+	env := []string{
+		"GIT_AUTHOR_NAME=" + fields[2], "GIT_AUTHOR_EMAIL=" + fields[3], "GIT_AUTHOR_DATE=" + fields[4],
+		"GIT_COMMITTER_NAME=" + fields[5], "GIT_COMMITTER_EMAIL=" + fields[6], "GIT_COMMITTER_DATE=" + fields[7],
+	}
+	newHash, err := c.runGitCommandEnv(context.Background(), env, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to rewrite commit %s: %w", commit.Hash, err)
+	}
+	return newHash, nil
+}
@@ -0,0 +1,67 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func commitTestFile(t *testing.T, repo, name, contents, message, date string) string {
+	t.Helper()
+	writeTestFile(t, repo, name, contents)
+	runTestGit(t, repo, "add", name)
+	runTestGit(t, repo, "-c", "user.name=Original Author", "-c", "user.email=author@example.com",
+		"commit", "-m", message, "--date", date)
+	return runTestGit(t, repo, "rev-parse", "HEAD")
+}
+
+func TestRewordCommits(t *testing.T) {
+	repo := newTestRepo(t)
+	client := &ExecGitClient{RepoPath: repo}
+	base := runTestGit(t, repo, "rev-parse", "HEAD")
+	first := commitTestFile(t, repo, "a.go", "package a\n", "wip", "2024-01-02T03:04:05+02:00")
+	second := commitTestFile(t, repo, "b.go", "package b\n", "wip 2", "2024-01-03T03:04:05+02:00")
+	third := commitTestFile(t, repo, "c.go", "package c\n", "feat: add c", "2024-01-04T03:04:05+02:00")
+	tree := runTestGit(t, repo, "rev-parse", "HEAD^{tree}")
+	identities := runTestGit(t, repo, "log", "--format=%an|%ae|%ad|%cn|%ce|%cd", "--date=raw", "-3")
+
+	commits, err := client.ListRewordCommits(base + "..HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []RewordCommit{{Hash: first, Message: "wip"}, {Hash: second, Message: "wip 2"}, {Hash: third, Message: "feat: add c"}}, commits)
+
+	diff, err := client.GetCommitDiff(second)
+	require.NoError(t, err)
+	assert.Contains(t, diff.Text, "+package b")
+	assert.NotContains(t, diff.Text, "a.go")
+
+	require.NoError(t, client.RewordCommits(commits, map[string]string{second: "feat: add b\n\nWith a body."}))
+
+	assert.Equal(t, "feat: add c\nfeat: add b\nwip", runTestGit(t, repo, "log", "--format=%s", "-3"))
+	assert.Equal(t, identities, runTestGit(t, repo, "log", "--format=%an|%ae|%ad|%cn|%ce|%cd", "--date=raw", "-3"))
+	assert.Equal(t, "With a body.", runTestGit(t, repo, "log", "-1", "--format=%b", "HEAD^"))
+	assert.Equal(t, first, runTestGit(t, repo, "rev-parse", "HEAD~2"))
+	assert.Equal(t, tree, runTestGit(t, repo, "rev-parse", "HEAD^{tree}"))
+	assert.Equal(t, "master", runTestGit(t, repo, "rev-parse", "--abbrev-ref", "HEAD"))
+}
+
+func TestListRewordCommitsRejectsInvalidRanges(t *testing.T) {
+	repo := newTestRepo(t)
+	client := &ExecGitClient{RepoPath: repo}
+	commitTestFile(t, repo, "a.go", "package a\n", "wip", "2024-01-02T03:04:05Z")
+	commitTestFile(t, repo, "b.go", "package b\n", "wip 2", "2024-01-03T03:04:05Z")
+
+	_, err := client.ListRewordCommits("HEAD~2..HEAD~1")
+	assert.ErrorContains(t, err, "must end at HEAD")
+
+	runTestGit(t, repo, "checkout", "-q", "-b", "side", "HEAD~1")
+	commitTestFile(t, repo, "c.go", "package c\n", "side", "2024-01-04T03:04:05Z")
+	runTestGit(t, repo, "checkout", "-q", "master")
+	runTestGit(t, repo, "merge", "-q", "--no-edit", "side")
+	_, err = client.ListRewordCommits("HEAD~3..HEAD")
+	assert.ErrorContains(t, err, "merge commits")
+
+	commits, err := client.ListRewordCommits("HEAD..HEAD")
+	require.NoError(t, err)
+	assert.Empty(t, commits)
+}
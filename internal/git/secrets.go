@@ -0,0 +1,277 @@
+package git
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	entropyMinLength = 20
+	privateKeyRule   = "private_key"
+	highEntropyRule  = "high_entropy_string"
+)
+
+var (
+	privateKeyBegin  = regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----`)
+	privateKeyEnd    = regexp.MustCompile(`-----END [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----`)
+	entropyCandidate = regexp.MustCompile(`["'\x60]([A-Za-z0-9+/=_\-]{20,})["'\x60]|[=:]\s*([A-Za-z0-9+/=_\-]{20,})\s*$`)
+	hunkRange        = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+)
+
+var builtinSecretRules = []secretRule{
+	{name: "aws_access_key_id", pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{name: "aws_secret_access_key", pattern: regexp.MustCompile(`(?i)aws.{0,20}?(?:secret|private).{0,20}?['"=:\s]+([A-Za-z0-9/+=]{40})\b`)},
+	{name: "gcp_api_key", pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{name: "gcp_service_account_key_id", pattern: regexp.MustCompile(`"private_key_id"\s*:\s*"([0-9a-f]{40})"`)},
+	{name: "github_token", pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`)},
+	{name: "slack_token", pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{name: "slack_webhook", pattern: regexp.MustCompile(`https://hooks\.slack\.com/services/[A-Za-z0-9/_-]+`)},
+}
+
+type secretRule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+type SecretFinding struct {
+	Path  string
+	Line  int
+	Rule  string
+	Added bool
+}
+
+func (f SecretFinding) String() string {
+	s := fmt.Sprintf("%s %s", f.Path, f.Rule)
+	if f.Line > 0 {
+		s = fmt.Sprintf("%s:%d %s", f.Path, f.Line, f.Rule)
+	}
+	if f.Added {
+		s += " (added)"
+	}
+	return s
+}
+
+type SecretScanner struct {
+	rules            []secretRule
+	entropyThreshold float64
+}
+
+func NewSecretScanner(patterns map[string]string, entropyThreshold float64) (*SecretScanner, error) {
+	s := &SecretScanner{rules: append([]secretRule{}, builtinSecretRules...), entropyThreshold: entropyThreshold}
+	names := make([]string, 0, len(patterns))
+	for name := range patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pattern, err := regexp.Compile(patterns[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret pattern %q: %w", name, err)
+		}
+		s.rules = append(s.rules, secretRule{name: name, pattern: pattern})
+	}
+	return s, nil
+}
+
+func secretMask(rule string) string {
+	return "[REDACTED:" + rule + "]"
+}
+
+type hunkCursor struct {
+	inHunk bool
+	inKey  bool
+	old    int
+	new    int
+}
+
+func (c *hunkCursor) advance(line string) (int, bool) {
+	if match := hunkRange.FindStringSubmatch(line); match != nil {
+		c.old, _ = strconv.Atoi(match[1])
+		c.new, _ = strconv.Atoi(match[2])
+		c.inHunk, c.inKey = true, false
+		return 0, false
+	}
+	if !c.inHunk || line == "" {
+		return 0, false
+	}
+	switch line[0] {
+	case '+':
+		c.new++
+		return c.new - 1, true
+	case '-':
+		c.old++
+		return c.old - 1, true
+	case ' ':
+		c.old++
+		c.new++
+		return c.new - 1, true
+	}
+	return 0, false
+}
+
+func (s *SecretScanner) maskDiff(path, diff string) (string, []SecretFinding) {
+	var findings []SecretFinding
+	var cursor hunkCursor
+	lines := strings.Split(diff, "\n")
+	for i, line := range lines {
+		lineNo, ok := cursor.advance(line)
+		if !ok {
+			continue
+		}
+		masked, rules := s.maskLine(line[1:], &cursor.inKey)
+		lines[i] = line[:1] + masked
+		for _, rule := range rules {
+			findings = append(findings, SecretFinding{Path: path, Line: lineNo, Rule: rule, Added: line[0] == '+'})
+		}
+	}
+	return strings.Join(lines, "\n"), findings
+}
+
+func (s *SecretScanner) maskSummary(path, summary string) (string, []SecretFinding) {
+	var findings []SecretFinding
+	inKey := false
+	lines := strings.Split(summary, "\n")
+	for i, line := range lines {
+		masked, rules := s.maskLine(line, &inKey)
+		lines[i] = masked
+		for _, rule := range rules {
+			findings = append(findings, SecretFinding{Path: path, Rule: rule, Added: strings.HasPrefix(line, "+") || strings.HasPrefix(line, "~ ")})
+		}
+	}
+	return strings.Join(lines, "\n"), findings
+}
+
+func (s *SecretScanner) maskLine(content string, inKey *bool) (string, []string) {
+	var rules []string
+	content, keyFound := maskPrivateKey(content, inKey)
+	if keyFound {
+		rules = append(rules, privateKeyRule)
+	}
+	for _, rule := range s.rules {
+		var found bool
+		if content, found = maskMatches(content, rule.pattern, secretMask(rule.name)); found {
+			rules = append(rules, rule.name)
+		}
+	}
+	if s.entropyThreshold > 0 {
+		var found bool
+		if content, found = s.maskHighEntropy(content); found {
+			rules = append(rules, highEntropyRule)
+		}
+	}
+	return content, rules
+}
+
+func maskPrivateKey(content string, inKey *bool) (string, bool) {
+	mask := secretMask(privateKeyRule)
+	if *inKey {
+		if end := privateKeyEnd.FindStringIndex(content); end != nil {
+			*inKey = false
+			return mask + content[end[1]:], false
+		}
+		if strings.TrimSpace(content) == "" {
+			return content, false
+		}
+		return mask, false
+	}
+	begin := privateKeyBegin.FindStringIndex(content)
+	if begin == nil {
+		return content, false
+	}
+	if end := privateKeyEnd.FindStringIndex(content[begin[1]:]); end != nil {
+		return content[:begin[0]] + mask + content[begin[1]+end[1]:], true
+	}
+	*inKey = true
+	return content[:begin[0]] + mask, true
+}
+
+func maskMatches(content string, pattern *regexp.Regexp, mask string) (string, bool) {
+	matches := pattern.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return content, false
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if len(m) >= 4 && m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+		b.WriteString(content[last:start])
+		b.WriteString(mask)
+		last = end
+	}
+	b.WriteString(content[last:])
+	return b.String(), true
+}
+
+func (s *SecretScanner) maskHighEntropy(content string) (string, bool) {
+	found := false
+	masked := entropyCandidate.ReplaceAllStringFunc(content, func(match string) string {
+		sub := entropyCandidate.FindStringSubmatch(match)
+		value := sub[1]
+		if value == "" {
+			value = sub[2]
+		}
+		if !looksRandom(value) || shannonEntropy(value) < s.entropyThreshold {
+			return match
+		}
+		found = true
+		return strings.Replace(match, value, secretMask(highEntropyRule), 1)
+	})
+	return masked, found
+}
+
+func looksRandom(value string) bool {
+	return len(value) >= entropyMinLength &&
+		strings.ContainsAny(value, "0123456789") &&
+		strings.ContainsAny(value, "abcdefghijklmnopqrstuvwxyz") &&
+		strings.ContainsAny(value, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+}
+
+func shannonEntropy(value string) float64 {
+	counts := map[rune]int{}
+	for _, r := range value {
+		counts[r]++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(len(value))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+func sortedFindings(set map[SecretFinding]struct{}) []SecretFinding {
+	if len(set) == 0 {
+		return nil
+	}
+	findings := make([]SecretFinding, 0, len(set))
+	for f := range set {
+		findings = append(findings, f)
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Rule < b.Rule
+	})
+	return findings
+}
+
+func HasAddedSecrets(findings []SecretFinding) bool {
+	for _, f := range findings {
+		if f.Added {
+			return true
+		}
+	}
+	return false
+}
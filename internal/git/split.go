@@ -0,0 +1,196 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type IndexSnapshot struct {
+	Head string
+	Tree string
+}
+
+type SplitFile struct {
+	Path    string
+	OldPath string
+	Hunks   int
+}
+
+type SplitSelection struct {
+	Path    string
+	OldPath string
+	Hunks   []int
+}
+
+func (c *ExecGitClient) SnapshotIndex() (IndexSnapshot, error) {
+	head, err := c.runGitCommand("rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return IndexSnapshot{}, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	tree, err := c.runGitCommand("write-tree")
+	if err != nil {
+		return IndexSnapshot{}, fmt.Errorf("failed to snapshot the index: %w", err)
+	}
+	return IndexSnapshot{Head: head, Tree: tree}, nil
+}
+
+func (c *ExecGitClient) ReadTree(tree string) error {
+	if _, err := c.runGitCommand("read-tree", tree); err != nil {
+		return fmt.Errorf("failed to restore the index: %w", err)
+	}
+	return nil
+}
+
+func (c *ExecGitClient) ListSplitFiles(s IndexSnapshot) ([]SplitFile, error) {
+	output, err := c.runGitCommand("diff", "--numstat", "-z", "-M", "--no-color", s.Head, s.Tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staged files: %w", err)
+	}
+	var files []SplitFile
+	for _, e := range parseNumstatEntries(output) {
+		f := SplitFile{Path: e.path, OldPath: e.oldPath}
+		if !e.binary {
+			f.Hunks = len(c.snapshotHunks(s, e.path).hunks)
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func (c *ExecGitClient) GetSplitDiff(s IndexSnapshot) (string, error) {
+	output, err := c.runGitCommand("diff", "--numstat", "-z", "-M", "--no-color", s.Head, s.Tree)
+	if err != nil {
+		return "", fmt.Errorf("failed to list staged files: %w", err)
+	}
+	entries := parseNumstatEntries(output)
+	paths := make([]string, len(entries))
+	for i, e := range entries {
+		paths[i] = e.path
+	}
+	attrs, _ := c.checkAttrs([]string{"filter", "diff", "yawn", "linguist-generated"}, paths)
+
+	found := map[SecretFinding]struct{}{}
+	var full, outline strings.Builder
+	for _, e := range entries {
+		hunks := c.snapshotHunks(s, e.path).hunks
+		f := classifyEntry(e, attrs[e.path], c.Redaction, func() string {
+			return c.readBlob(s.Tree+":"+e.path, generatedHeadBytes)
+		})
+		if f.category != catNormal {
+			line := fmt.Sprintf("### %s (%s, %d hunks not shown)\n", e.path, f.label(), len(hunks))
+			full.WriteString(line)
+			outline.WriteString(line)
+			continue
+		}
+		for i, hunk := range hunks {
+			hunk = c.maskSecrets(found, e.path, hunk, false)
+			label := fmt.Sprintf("### %s#%d\n", e.path, i+1)
+			full.WriteString(label + hunk)
+			header, _, _ := strings.Cut(hunk, "\n")
+			outline.WriteString(label + header + "\n")
+		}
+	}
+	if full.Len() <= MaxDiffBytes {
+		return full.String(), nil
+	}
+	return "(hunk bodies omitted, the staged diff is too large)\n" + outline.String(), nil
+}
+
+func (c *ExecGitClient) CommitSelection(s IndexSnapshot, selections []SplitSelection, message string) error {
+	if _, err := c.runGitCommand("read-tree", "HEAD"); err != nil {
+		return fmt.Errorf("failed to reset the index: %w", err)
+	}
+	for _, sel := range selections {
+		var err error
+		if len(sel.Hunks) > 0 {
+			err = c.applySnapshotHunks(s, sel.Path, sel.Hunks)
+		} else {
+			err = c.stageFromTree(s.Tree, sel.Path, sel.OldPath)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return c.Commit(message)
+}
+
+func (c *ExecGitClient) stageFromTree(tree string, paths ...string) error {
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		entry, err := c.runGitCommand("ls-tree", tree, "--", p)
+		if err != nil {
+			return fmt.Errorf("failed to read %s from the staged tree: %w", p, err)
+		}
+		if entry == "" {
+			_, err = c.runGitCommand("update-index", "--force-remove", "--", p)
+		} else {
+			meta, _, _ := strings.Cut(entry, "\t")
+			fields := strings.Fields(meta)
+			if len(fields) != 3 {
+				return fmt.Errorf("unexpected ls-tree output for %s: %q", p, entry)
+			}
+			_, err = c.runGitCommand("update-index", "--add", "--cacheinfo", fields[0]+","+fields[2]+","+p)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to stage %s: %w", p, err)
+		}
+	}
+	return nil
+}
+
+func (c *ExecGitClient) snapshotHunks(s IndexSnapshot, p string) fileHunks {
+	out, err := c.runGitCommand("diff", "--no-color", "--no-renames", "--no-ext-diff", s.Head, s.Tree, "--", p)
+	if err != nil || out == "" {
+		return fileHunks{}
+	}
+	return splitHunks(numstatEntry{path: p}, out+"\n")
+}
+
+func (c *ExecGitClient) applySnapshotHunks(s IndexSnapshot, p string, selected []int) error {
+	f := c.snapshotHunks(s, p)
+	var b strings.Builder
+	b.WriteString(f.header)
+	for _, n := range selected {
+		if n < 1 || n > len(f.hunks) {
+			return fmt.Errorf("%s has no hunk %d", p, n)
+		}
+		b.WriteString(f.hunks[n-1])
+	}
+
+	patch, err := os.CreateTemp("", "yawn-split-*.patch")
+	if err != nil {
+		return fmt.Errorf("failed to create patch file: %w", err)
+	}
+	defer func() { _ = os.Remove(patch.Name()) }()
+	if _, err := patch.WriteString(b.String()); err != nil {
+		_ = patch.Close()
+		return fmt.Errorf("failed to write patch file: %w", err)
+	}
+	if err := patch.Close(); err != nil {
+		return fmt.Errorf("failed to write patch file: %w", err)
+	}
+	if _, err := c.runGitCommand("apply", "--cached", "--recount", patch.Name()); err != nil {
+		return fmt.Errorf("failed to stage hunks %s of %s: %w", formatHunkList(selected), p, err)
+	}
+	return nil
+}
+
+func formatHunkList(hunks []int) string {
+	parts := make([]string, len(hunks))
+	for i, n := range hunks {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (c *ExecGitClient) GetEditor() (string, error) {
+	editor, err := c.runGitCommand("var", "GIT_EDITOR")
+	if err != nil {
+		return "", fmt.Errorf("failed to determine the editor: %w", err)
+	}
+	return editor, nil
+}
@@ -0,0 +1,100 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func numberedLines(n int, change map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if line, ok := change[i]; ok {
+			b.WriteString(line + "\n")
+			continue
+		}
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	return b.String()
+}
+
+func TestExecGitClient_CommitSelectionSplitsStagedChanges(t *testing.T) {
+	repo := newTestRepo(t)
+	writeTestFile(t, repo, "app.txt", numberedLines(40, nil))
+	writeTestFile(t, repo, "old.txt", "old\n")
+	writeTestFile(t, repo, "moved.txt", numberedLines(10, nil))
+	runTestGit(t, repo, "add", ".")
+	runTestGit(t, repo, "commit", "-m", "initial")
+
+	writeTestFile(t, repo, "app.txt", numberedLines(40, map[int]string{2: "first change", 38: "second change"}))
+	writeTestFile(t, repo, "new.txt", "new\n")
+	runTestGit(t, repo, "rm", "-q", "old.txt")
+	runTestGit(t, repo, "mv", "moved.txt", "renamed.txt")
+	runTestGit(t, repo, "add", ".")
+	writeTestFile(t, repo, "new.txt", "new\nunstaged\n")
+
+	client := &ExecGitClient{RepoPath: repo}
+	snapshot, err := client.SnapshotIndex()
+	require.NoError(t, err)
+
+	files, err := client.ListSplitFiles(snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, []SplitFile{
+		{Path: "app.txt", Hunks: 2},
+		{Path: "new.txt", Hunks: 1},
+		{Path: "old.txt", Hunks: 1},
+		{Path: "renamed.txt", OldPath: "moved.txt", Hunks: 1},
+	}, files)
+
+	require.NoError(t, client.CommitSelection(snapshot, []SplitSelection{
+		{Path: "app.txt", Hunks: []int{1}},
+		{Path: "new.txt"},
+	}, "feat: first"))
+	require.NoError(t, client.CommitSelection(snapshot, []SplitSelection{
+		{Path: "app.txt", Hunks: []int{2}},
+		{Path: "old.txt"},
+	}, "chore: second"))
+	require.NoError(t, client.ReadTree(snapshot.Tree))
+
+	assert.Equal(t, "chore: second\nfeat: first\ninitial", runTestGit(t, repo, "log", "-3", "--format=%s"))
+	assert.Equal(t, "M\tapp.txt\nA\tnew.txt", runTestGit(t, repo, "show", "--format=", "--name-status", "HEAD~1"))
+	assert.Contains(t, runTestGit(t, repo, "show", "HEAD~1:app.txt"), "first change\n")
+	assert.NotContains(t, runTestGit(t, repo, "show", "HEAD~1:app.txt"), "second change")
+	assert.Equal(t, "D\tmoved.txt\nA\trenamed.txt", runTestGit(t, repo, "diff", "--cached", "--name-status", "--no-renames"))
+	assert.Equal(t, "M\tnew.txt", runTestGit(t, repo, "diff", "--name-status"))
+}
+
+func TestExecGitClient_GetSplitDiffLabelsSnapshotHunks(t *testing.T) {
+	repo := newTestRepo(t)
+	writeTestFile(t, repo, "app.txt", numberedLines(40, nil))
+	writeTestFile(t, repo, "go.sum", "a v1\n")
+	runTestGit(t, repo, "add", ".")
+	runTestGit(t, repo, "commit", "-m", "initial")
+
+	writeTestFile(t, repo, "app.txt", numberedLines(40, map[int]string{2: "first change", 38: "second change"}))
+	writeTestFile(t, repo, "go.sum", "a v2\n")
+	runTestGit(t, repo, "add", ".")
+
+	client := &ExecGitClient{RepoPath: repo}
+	snapshot, err := client.SnapshotIndex()
+	require.NoError(t, err)
+
+	diff, err := client.GetSplitDiff(snapshot)
+	require.NoError(t, err)
+	assert.Regexp(t, `(?s)^### app.txt#1\n@@ -1,5 \+1,5 @@.*\+first change\n.*### app.txt#2\n@@ .*\+second change\n`, diff)
+	assert.Contains(t, diff, "### go.sum (lockfile, 1 hunks not shown)\n")
+	assert.NotContains(t, diff, "a v2")
+
+	writeTestFile(t, repo, "big.txt", strings.Repeat("big line\n", MaxDiffBytes/8))
+	runTestGit(t, repo, "add", ".")
+	snapshot, err = client.SnapshotIndex()
+	require.NoError(t, err)
+
+	diff, err = client.GetSplitDiff(snapshot)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "### app.txt#2\n@@ -35,6 +35,6 @@ line 34\n### big.txt#1\n@@ -0,0 +1,")
+	assert.NotContains(t, diff, "second change")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func (c *ExecGitClient) FindBranchBase(branch string) (string, error) {
@@ -105,20 +106,20 @@ func (c *ExecGitClient) GetCommitCountRange(base string) (int, error) {
 	return count, nil
 }
 
-func (c *ExecGitClient) GetDiffRange(base string) (string, error) {
-	numstatOutput, err := c.runGitCommand("diff", "--numstat", "-z", "--no-renames", "--no-color", base, "HEAD")
+func (c *ExecGitClient) GetDiffRange(base string) (Diff, error) {
+	numstatOutput, err := c.runGitCommand("diff", "--numstat", "-z", "-M", "-C", "--no-color", base, "HEAD")
 	if err != nil {
-		return "", fmt.Errorf("failed to get diff stats: %w", err)
+		return Diff{}, fmt.Errorf("failed to get diff stats: %w", err)
 	}
-	return c.buildFilteredDiff(numstatOutput, []string{"diff", "--no-color", base, "HEAD"}), nil
+	return c.buildFilteredDiff(numstatOutput, diffSource{args: []string{"diff", "--no-color", base, "HEAD"}, oldRev: base, newRev: "HEAD"}), nil
 }
 
-func (c *ExecGitClient) GetDiffCachedRange(base string) (string, error) {
-	numstatOutput, err := c.runGitCommand("diff", "--cached", "--numstat", "-z", "--no-renames", "--no-color", base)
+func (c *ExecGitClient) GetDiffCachedRange(base string) (Diff, error) {
+	numstatOutput, err := c.runGitCommand("diff", "--cached", "--numstat", "-z", "-M", "-C", "--no-color", base)
 	if err != nil {
-		return "", fmt.Errorf("failed to get diff stats: %w", err)
+		return Diff{}, fmt.Errorf("failed to get diff stats: %w", err)
 	}
-	return c.buildFilteredDiff(numstatOutput, []string{"diff", "--cached", "--no-color", base}), nil
+	return c.buildFilteredDiff(numstatOutput, cachedDiffSource(base)), nil
 }
 
 func (c *ExecGitClient) GetDiffNumStatRange(base string) (int, int, error) {
@@ -139,6 +140,25 @@ func (c *ExecGitClient) GetDiffNumStatCachedRange(base string) (int, int, error)
 	return add, del, nil
 }
 
+func (c *ExecGitClient) CreateBackupRef(branch string) (string, error) {
+	base := "refs/yawn/backup/" + branch + "/" + time.Now().Format("20060102-150405")
+	ref := base
+	for n := 2; ; n++ {
+		existing, err := c.ResolveRef(ref)
+		if err != nil {
+			return "", err
+		}
+		if existing == "" {
+			break
+		}
+		ref = fmt.Sprintf("%s-%d", base, n)
+	}
+	if _, err := c.runGitCommand("update-ref", "-m", "yawn backup", ref, "HEAD", ""); err != nil {
+		return "", fmt.Errorf("failed to create backup ref %s: %w", ref, err)
+	}
+	return ref, nil
+}
+
 func (c *ExecGitClient) ResetSoft(commit string) error {
 	if _, err := c.runGitCommand("reset", "--soft", commit); err != nil {
 		return fmt.Errorf("failed to reset: %w", err)
@@ -146,11 +166,16 @@ func (c *ExecGitClient) ResetSoft(commit string) error {
 	return nil
 }
 
-func (c *ExecGitClient) Stash() error {
+func (c *ExecGitClient) Stash() (string, error) {
+	before, _ := c.runGitCommand("rev-parse", "--quiet", "--verify", "refs/stash")
 	if _, err := c.runGitCommand("stash"); err != nil {
-		return fmt.Errorf("failed to stash: %w", err)
+		return "", fmt.Errorf("failed to stash: %w", err)
 	}
-	return nil
+	after, _ := c.runGitCommand("rev-parse", "--quiet", "--verify", "refs/stash")
+	if after == before {
+		return "", nil
+	}
+	return after, nil
 }
 
 func (c *ExecGitClient) StashPop() error {
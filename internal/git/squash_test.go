@@ -76,8 +76,8 @@ func TestExecGitClient_GetDiffCachedRangeIncludesStagedChanges(t *testing.T) {
 	diff, err := client.GetDiffCachedRange(base)
 
 	require.NoError(t, err)
-	assert.Contains(t, diff, "committed.txt")
-	assert.Contains(t, diff, "dirty.txt")
+	assert.Contains(t, diff.Text, "committed.txt")
+	assert.Contains(t, diff.Text, "dirty.txt")
 }
 
 func TestExecGitClient_AmendCommitUsesMessage(t *testing.T) {
@@ -94,6 +94,42 @@ func TestExecGitClient_AmendCommitUsesMessage(t *testing.T) {
 	assert.Equal(t, "feat: generated title\n\nGenerated body", runTestGit(t, repo, "log", "-1", "--format=%B"))
 }
 
+func TestExecGitClient_CreateBackupRefPointsAtHead(t *testing.T) {
+	repo := newTestRepo(t)
+	client := &ExecGitClient{RepoPath: repo}
+
+	ref, err := client.CreateBackupRef("feature/login")
+
+	require.NoError(t, err)
+	assert.Regexp(t, `^refs/yawn/backup/feature/login/\d{8}-\d{6}$`, ref)
+	assert.Equal(t, runTestGit(t, repo, "rev-parse", "HEAD"), runTestGit(t, repo, "rev-parse", ref))
+
+	runTestGit(t, repo, "update-ref", ref+"-2", "HEAD")
+	next, err := client.CreateBackupRef("feature/login")
+	require.NoError(t, err)
+	assert.Regexp(t, `^refs/yawn/backup/feature/login/\d{8}-\d{6}(-3)?$`, next)
+	assert.NotEqual(t, ref, next)
+}
+
+func TestExecGitClient_StashReturnsStashID(t *testing.T) {
+	repo := newTestRepo(t)
+	client := &ExecGitClient{RepoPath: repo}
+
+	id, err := client.Stash()
+	require.NoError(t, err)
+	assert.Empty(t, id)
+
+	writeTestFile(t, repo, "README.md", "changed\n")
+	id, err = client.Stash()
+	require.NoError(t, err)
+	assert.Equal(t, runTestGit(t, repo, "rev-parse", "refs/stash"), id)
+
+	writeTestFile(t, repo, "untracked.txt", "new\n")
+	again, err := client.Stash()
+	require.NoError(t, err)
+	assert.Empty(t, again)
+}
+
 func newTestRepo(t *testing.T) string {
 	t.Helper()
 	repo := t.TempDir()
@@ -108,6 +144,7 @@ func newTestRepo(t *testing.T) string {
 
 func writeTestFile(t *testing.T, repo, name, contents string) {
 	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(repo, name)), 0o755))
 	require.NoError(t, os.WriteFile(filepath.Join(repo, name), []byte(contents), 0o644))
 }
 
@@ -0,0 +1,100 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type ChangedFile struct {
+	Path      string
+	Status    string
+	Additions int
+	Deletions int
+	Binary    bool
+}
+
+func (f ChangedFile) StatusLabel() string {
+	switch f.Status {
+	case "M":
+		return "modified"
+	case "A":
+		return "added"
+	case "D":
+		return "deleted"
+	case "T":
+		return "type changed"
+	case "?":
+		return "untracked"
+	}
+	return f.Status
+}
+
+func (c *ExecGitClient) ListUnstagedFiles() ([]ChangedFile, error) {
+	statusOutput, err := c.runGitCommand("diff", "--name-status", "-z", "--no-renames", "--no-color")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unstaged files: %w", err)
+	}
+	numstatOutput, err := c.runGitCommand("diff", "--numstat", "-z", "--no-renames", "--no-color")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unstaged changes: %w", err)
+	}
+	stats := map[string]numstatEntry{}
+	for _, e := range parseNumstatEntries(numstatOutput) {
+		stats[e.path] = e
+	}
+
+	var files []ChangedFile
+	records := splitNumstatRecords(statusOutput)
+	for i := 0; i+1 < len(records); i += 2 {
+		f := ChangedFile{Path: records[i+1], Status: records[i][:1]}
+		if e, ok := stats[f.Path]; ok {
+			f.Binary = e.binary
+			f.Additions, _ = strconv.Atoi(e.additions)
+			f.Deletions, _ = strconv.Atoi(e.deletions)
+		}
+		files = append(files, f)
+	}
+
+	untracked, err := c.runGitCommand("ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	for _, p := range splitNumstatRecords(untracked) {
+		files = append(files, c.untrackedFile(p))
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+func (c *ExecGitClient) untrackedFile(p string) ChangedFile {
+	f := ChangedFile{Path: p, Status: "?"}
+	content, err := os.ReadFile(filepath.Join(c.RepoPath, p))
+	if err != nil {
+		return f
+	}
+	if bytes.IndexByte(content, 0) >= 0 {
+		f.Binary = true
+		return f
+	}
+	f.Additions = strings.Count(string(content), "\n")
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		f.Additions++
+	}
+	return f
+}
+
+func (c *ExecGitClient) StagePaths(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	args := append([]string{"add", "-A", "--"}, paths...)
+	if _, err := c.runGitCommand(args...); err != nil {
+		return fmt.Errorf("failed to stage files: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package git
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	structuredMaxBytes   = 1 << 20
+	structuredValueBytes = 80
+)
+
+type structuredParser func(content string) (any, error)
+
+type structuredChange struct {
+	op     byte
+	key    string
+	before any
+	after  any
+}
+
+func structuredParserFor(p string) structuredParser {
+	base := strings.ToLower(path.Base(p))
+	switch {
+	case strings.HasSuffix(base, ".env.example"):
+		return parseEnvFile
+	case strings.HasSuffix(base, ".json"):
+		return parseJSON
+	case strings.HasSuffix(base, ".yaml"), strings.HasSuffix(base, ".yml"):
+		return parseYAML
+	case strings.HasSuffix(base, ".toml"):
+		return parseTOML
+	}
+	return nil
+}
+
+func parseJSON(content string) (any, error) {
+	var doc any
+	err := json.Unmarshal([]byte(content), &doc)
+	return doc, err
+}
+
+type yamlDocuments []any
+
+func parseYAML(content string) (any, error) {
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	var docs yamlDocuments
+	for {
+		var doc any
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	if len(docs) == 1 {
+		return docs[0], nil
+	}
+	return docs, nil
+}
+
+func yamlDocumentList(v any) []any {
+	switch v := v.(type) {
+	case yamlDocuments:
+		return v
+	case nil:
+		return nil
+	}
+	return []any{v}
+}
+
+func parseTOML(content string) (any, error) {
+	var doc map[string]any
+	err := toml.Unmarshal([]byte(content), &doc)
+	return doc, err
+}
+
+func parseEnvFile(content string) (any, error) {
+	doc := map[string]any{}
+	for line := range strings.SplitSeq(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid env line %q", line)
+		}
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else {
+			value = strings.Trim(value, "'")
+		}
+		doc[strings.TrimSpace(key)] = value
+	}
+	return doc, nil
+}
+
+func (c *ExecGitClient) structuredSummary(src diffSource, e numstatEntry) string {
+	parse := structuredParserFor(e.path)
+	if parse == nil {
+		return ""
+	}
+	oldPath := e.path
+	if e.oldPath != "" {
+		oldPath = e.oldPath
+	}
+	before, ok := c.readStructured(parse, src.oldRev+":"+oldPath)
+	if !ok {
+		return ""
+	}
+	after, ok := c.readStructured(parse, src.newRev+":"+e.path)
+	if !ok {
+		return ""
+	}
+	return formatStructuredSummary(e, diffStructured(before, after))
+}
+
+func (c *ExecGitClient) readStructured(parse structuredParser, spec string) (any, bool) {
+	content := c.readBlob(spec, structuredMaxBytes)
+	if len(content) >= structuredMaxBytes {
+		return nil, false
+	}
+	if strings.TrimSpace(content) == "" {
+		return nil, true
+	}
+	doc, err := parse(content)
+	if err != nil {
+		return nil, false
+	}
+	return normalizeStructured(doc), true
+}
+
+func normalizeStructured(v any) any {
+	if docs, ok := v.(yamlDocuments); ok {
+		normalized := make(yamlDocuments, len(docs))
+		for i, doc := range docs {
+			normalized[i] = normalizeStructured(doc)
+		}
+		return normalized
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		m := make(map[string]any, rv.Len())
+		for _, key := range rv.MapKeys() {
+			m[fmt.Sprint(key.Interface())] = normalizeStructured(rv.MapIndex(key).Interface())
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		list := make([]any, rv.Len())
+		for i := range list {
+			list[i] = normalizeStructured(rv.Index(i).Interface())
+		}
+		return list
+	}
+	return v
+}
+
+func diffStructured(before, after any) []structuredChange {
+	var changes []structuredChange
+	_, beforeMulti := before.(yamlDocuments)
+	_, afterMulti := after.(yamlDocuments)
+	if !beforeMulti && !afterMulti {
+		diffStructuredValue("", before, after, &changes)
+		return changes
+	}
+	beforeDocs, afterDocs := yamlDocumentList(before), yamlDocumentList(after)
+	for i := range max(len(beforeDocs), len(afterDocs)) {
+		key := fmt.Sprintf("documents[%d]", i)
+		switch {
+		case i >= len(beforeDocs):
+			addStructuredLeaves(key, '+', afterDocs[i], &changes)
+		case i >= len(afterDocs):
+			addStructuredLeaves(key, '-', beforeDocs[i], &changes)
+		default:
+			diffStructuredValue(key, beforeDocs[i], afterDocs[i], &changes)
+		}
+	}
+	return changes
+}
+
+func diffStructuredValue(key string, before, after any, changes *[]structuredChange) {
+	beforeMap, beforeIsMap := before.(map[string]any)
+	afterMap, afterIsMap := after.(map[string]any)
+	if (beforeIsMap || before == nil) && (afterIsMap || after == nil) && (beforeIsMap || afterIsMap) {
+		diffStructuredMaps(key, beforeMap, afterMap, changes)
+		return
+	}
+	beforeList, beforeIsList := before.([]any)
+	afterList, afterIsList := after.([]any)
+	if beforeIsList && afterIsList {
+		diffStructuredLists(key, beforeList, afterList, changes)
+		return
+	}
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, structuredChange{op: '~', key: key, before: before, after: after})
+	}
+}
+
+func diffStructuredMaps(key string, before, after map[string]any, changes *[]structuredChange) {
+	keys := make([]string, 0, len(before)+len(after))
+	for k := range before {
+		keys = append(keys, k)
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		child := k
+		if key != "" {
+			child = key + "." + k
+		}
+		b, inBefore := before[k]
+		a, inAfter := after[k]
+		switch {
+		case !inBefore:
+			addStructuredLeaves(child, '+', a, changes)
+		case !inAfter:
+			addStructuredLeaves(child, '-', b, changes)
+		default:
+			diffStructuredValue(child, b, a, changes)
+		}
+	}
+}
+
+func diffStructuredLists(key string, before, after []any, changes *[]structuredChange) {
+	for i := range max(len(before), len(after)) {
+		child := fmt.Sprintf("%s[%d]", key, i)
+		switch {
+		case i >= len(before):
+			*changes = append(*changes, structuredChange{op: '+', key: child, after: after[i]})
+		case i >= len(after):
+			*changes = append(*changes, structuredChange{op: '-', key: child, before: before[i]})
+		default:
+			diffStructuredValue(child, before[i], after[i], changes)
+		}
+	}
+}
+
+func addStructuredLeaves(key string, op byte, value any, changes *[]structuredChange) {
+	if m, ok := value.(map[string]any); ok && len(m) > 0 {
+		if op == '+' {
+			diffStructuredMaps(key, nil, m, changes)
+		} else {
+			diffStructuredMaps(key, m, nil, changes)
+		}
+		return
+	}
+	change := structuredChange{op: op, key: key}
+	if op == '+' {
+		change.after = value
+	} else {
+		change.before = value
+	}
+	*changes = append(*changes, change)
+}
+
+func formatStructuredSummary(e numstatEntry, changes []structuredChange) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (key-level summary, formatting and key order ignored)\n", e.displayPath())
+	if len(changes) == 0 {
+		b.WriteString("no key-level changes")
+		return b.String()
+	}
+	for _, change := range changes {
+		key := change.key
+		if key == "" {
+			key = "(root)"
+		}
+		switch change.op {
+		case '+':
+			fmt.Fprintf(&b, "+ %s: %s\n", key, formatStructuredValue(change.after))
+		case '-':
+			fmt.Fprintf(&b, "- %s: %s\n", key, formatStructuredValue(change.before))
+		default:
+			fmt.Fprintf(&b, "~ %s: %s -> %s\n", key, formatStructuredValue(change.before), formatStructuredValue(change.after))
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func formatStructuredValue(v any) string {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		s = strconv.Quote(v)
+	case map[string]any:
+		return fmt.Sprintf("{%d keys}", len(v))
+	case []any:
+		encoded, err := json.Marshal(v)
+		if err != nil || len(encoded) > structuredValueBytes {
+			return fmt.Sprintf("[%d items]", len(v))
+		}
+		return string(encoded)
+	default:
+		s = fmt.Sprint(v)
+	}
+	if len(s) > structuredValueBytes {
+		s = truncateRunes(s, structuredValueBytes) + "..."
+	}
+	return s
+}
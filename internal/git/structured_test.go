@@ -0,0 +1,135 @@
+package git
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffStructured(t *testing.T) {
+	before := map[string]any{
+		"server": map[string]any{"port": 8080.0, "host": "localhost"},
+		"legacy": true,
+		"tags":   []any{"a", "b"},
+	}
+	after := map[string]any{
+		"server":   map[string]any{"port": 9090.0, "host": "localhost"},
+		"features": map[string]any{"beta": true, "limits": map[string]any{"rps": 10.0}},
+		"tags":     []any{"a", "c", "d"},
+	}
+
+	summary := formatStructuredSummary(numstatEntry{path: "config.json"}, diffStructured(before, after))
+
+	assert.Equal(t, strings.Join([]string{
+		"config.json (key-level summary, formatting and key order ignored)",
+		"+ features.beta: true",
+		"+ features.limits.rps: 10",
+		"- legacy: true",
+		"~ server.port: 8080 -> 9090",
+		`~ tags[1]: "b" -> "c"`,
+		`+ tags[2]: "d"`,
+	}, "\n"), summary)
+}
+
+func TestDiffStructured_NoChanges(t *testing.T) {
+	doc := map[string]any{"a": 1.0, "b": []any{"x"}}
+	summary := formatStructuredSummary(numstatEntry{path: "a.json"}, diffStructured(doc, doc))
+	assert.True(t, strings.HasSuffix(summary, "\nno key-level changes"))
+}
+
+func TestDiffStructured_YAMLDocuments(t *testing.T) {
+	before, err := parseYAML("kind: Service\nport: 80\n---\nkind: Deployment\nspec:\n  replicas: 1\n")
+	require.NoError(t, err)
+	after, err := parseYAML("kind: Service\nport: 80\n---\nkind: Deployment\nspec:\n  replicas: 3\n---\nkind: ConfigMap\n")
+	require.NoError(t, err)
+	single, err := parseYAML("kind: Service\nport: 80\n")
+	require.NoError(t, err)
+
+	summary := formatStructuredSummary(numstatEntry{path: "deploy.yaml"}, diffStructured(normalizeStructured(before), normalizeStructured(after)))
+	assert.Equal(t, strings.Join([]string{
+		"deploy.yaml (key-level summary, formatting and key order ignored)",
+		"~ documents[1].spec.replicas: 1 -> 3",
+		`+ documents[2].kind: "ConfigMap"`,
+	}, "\n"), summary)
+
+	changes := diffStructured(normalizeStructured(single), normalizeStructured(before))
+	assert.Equal(t, []structuredChange{
+		{op: '+', key: "documents[1].kind", after: "Deployment"},
+		{op: '+', key: "documents[1].spec.replicas", after: 1},
+	}, changes)
+}
+
+func TestStructuredParsers(t *testing.T) {
+	tests := []struct {
+		path    string
+		content string
+		want    any
+	}{
+		{"config.json", `{"a": {"b": [1, "x"]}}`, map[string]any{"a": map[string]any{"b": []any{1.0, "x"}}}},
+		{"deploy/values.yaml", "a:\n  b:\n    - 1\n    - x\n1: one\n", map[string]any{"a": map[string]any{"b": []any{1, "x"}}, "1": "one"}},
+		{"Cargo.toml", "[package]\nname = \"yawn\"\n[[bin]]\nname = \"a\"\n", map[string]any{"package": map[string]any{"name": "yawn"}, "bin": []any{map[string]any{"name": "a"}}}},
+		{".env.example", "# comment\nexport API_URL=\"https://example.com\"\nDEBUG='false'\nEMPTY=\n", map[string]any{"API_URL": "https://example.com", "DEBUG": "false", "EMPTY": ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			parse := structuredParserFor(tt.path)
+			require.NotNil(t, parse)
+			doc, err := parse(tt.content)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, normalizeStructured(doc))
+		})
+	}
+
+	assert.Nil(t, structuredParserFor("main.go"))
+	assert.Nil(t, structuredParserFor(".env"))
+}
+
+func TestFormatStructuredValue(t *testing.T) {
+	assert.Equal(t, "null", formatStructuredValue(nil))
+	assert.Equal(t, `"x"`, formatStructuredValue("x"))
+	assert.Equal(t, "{2 keys}", formatStructuredValue(map[string]any{"a": 1, "b": 2}))
+	assert.Equal(t, `["a",1]`, formatStructuredValue([]any{"a", 1}))
+	assert.Equal(t, "[100 items]", formatStructuredValue(make([]any, 100)))
+	assert.Equal(t, `"`+strings.Repeat("9", 79)+"...", formatStructuredValue(strings.Repeat("9", 100)))
+	assert.Equal(t, `"`+strings.Repeat("ж", 39)+"...", formatStructuredValue(strings.Repeat("ж", 100)))
+}
+
+func TestExecGitClient_GetDiffSummarizesReformattedJSON(t *testing.T) {
+	repo := newTestRepo(t)
+	var before strings.Builder
+	before.WriteString("{\n")
+	for i := range 30 {
+		before.WriteString("  \"key" + string(rune('a'+i%26)) + strings.Repeat("x", i) + "\": \"value\",\n")
+	}
+	before.WriteString("  \"port\": 8080\n}\n")
+	writeTestFile(t, repo, "settings.json", before.String())
+	writeTestFile(t, repo, "small.json", "{\"a\": 1}\n")
+	runTestGit(t, repo, "add", ".")
+	runTestGit(t, repo, "commit", "-m", "initial")
+
+	doc, err := parseJSON(before.String())
+	require.NoError(t, err)
+	doc.(map[string]any)["port"] = 9090
+	writeTestFile(t, repo, "settings.json", mustMarshalCompactJSON(t, doc))
+	writeTestFile(t, repo, "small.json", "{\"a\": 2}\n")
+	runTestGit(t, repo, "add", ".")
+
+	client := &ExecGitClient{RepoPath: repo}
+	diff, err := client.GetDiff()
+	require.NoError(t, err)
+
+	assert.Contains(t, diff.Text, "settings.json (key-level summary, formatting and key order ignored)\n~ port: 8080 -> 9090")
+	assert.NotContains(t, diff.Text, "\"value\"")
+	assert.Contains(t, diff.Text, "small.json (key-level summary, formatting and key order ignored)\n~ a: 1 -> 2")
+}
+
+func mustMarshalCompactJSON(t *testing.T, doc any) string {
+	t.Helper()
+	encoded, err := json.Marshal(doc)
+	require.NoError(t, err)
+	return string(encoded)
+}
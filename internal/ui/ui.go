@@ -3,9 +3,13 @@ package ui
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/briandowns/spinner"
 	"github.com/fatih/color"
@@ -28,6 +32,10 @@ var (
 	colorYellow = color.New(color.FgYellow)
 )
 
+func SetInput(r io.Reader) {
+	reader = bufio.NewReader(r)
+}
+
 func AskYesNo(prompt string, defaultYes bool) bool {
 	hint := "[y/N]"
 	if defaultYes {
@@ -102,6 +110,34 @@ func ClearLine() {
 	}
 }
 
+func ClearStreamedText(text string) {
+	if !isTerminal {
+		fmt.Println()
+		return
+	}
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width = 0
+	}
+	fmt.Print("\r\033[K")
+	for range countTerminalRows(text, width) - 1 {
+		fmt.Print("\033[1A\r\033[K")
+	}
+}
+
+func countTerminalRows(text string, width int) int {
+	rows := 0
+	for _, line := range strings.Split(text, "\n") {
+		n := utf8.RuneCountInString(line)
+		if width <= 0 || n <= width {
+			rows++
+			continue
+		}
+		rows += (n + width - 1) / width
+	}
+	return rows
+}
+
 func PrintRepoLink(message string, url string) {
 	if isTerminal {
 		link := fmt.Sprintf("\033]8;;%s\033\\%s\033]8;;\033\\", url, color.BlueString(url))
@@ -178,15 +214,216 @@ func PrintForcePushPreview(remoteCommits, localCommits []string) {
 	}
 }
 
-func PrintPreGenerationInfo(branchName string, additions int, deletions int, model string) {
+func PrintPreGenerationInfo(branchName string, additions int, deletions int, model string, diffContext string) {
 	msg := colorBlue.Sprintf("Branch: %s | Changes: %s %s | Model: %s",
 		colorYellow.Sprint(branchName),
 		colorGreen.Sprintf("↑ %d", additions),
 		colorRed.Sprintf("↓ %d", deletions),
 		colorYellow.Sprint(model),
 	)
+	if diffContext != "" {
+		msg += colorBlue.Sprintf(" | Context: %s", colorYellow.Sprint(diffContext))
+	}
 	if Version != "" {
 		msg += colorBlue.Sprintf(" | yawn %s", colorYellow.Sprint(Version))
 	}
 	fmt.Printf("%s %s\n", infoPrefix, msg)
 }
+
+func PrintMaskedSecrets(findings []string) {
+	if len(findings) == 0 {
+		return
+	}
+	fmt.Printf("%s Masked %d secret(s) before sending the diff:\n", infoPrefix, len(findings))
+	for _, f := range findings {
+		fmt.Printf("  %s\n", colorYellow.Sprint(f))
+	}
+}
+
+func PrintStagingViolations(violations []string) {
+	fmt.Printf("%s %d untracked file(s) should not be staged:\n", errorPrefix, len(violations))
+	for _, v := range violations {
+		fmt.Printf("  %s\n", colorRed.Sprint(v))
+	}
+}
+
+func AskStagingGuardAction(allowStage bool) string {
+	options := "[g] add to .gitignore  [e] add to .git/info/exclude"
+	if allowStage {
+		options += "  [s] stage anyway"
+	}
+	fmt.Printf("%s[Enter] cancel  %s: ", promptPrefix, options)
+	key := readSingleKey()
+	ClearLine()
+	return key
+}
+
+func PickFiles(labels []string) []int {
+	selected := make([]bool, len(labels))
+	for {
+		fmt.Printf("%s Select files to stage:\n", infoPrefix)
+		for i, label := range labels {
+			mark := "[ ]"
+			if selected[i] {
+				mark = colorGreen.Sprint("[x]")
+			}
+			fmt.Printf("  %s %2d. %s\n", mark, i+1, label)
+		}
+		fmt.Printf("%sToggle by number (e.g. 1 3-5), [a] all, [n] none, [Enter] done: ", promptPrefix)
+		input, err := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if input == "" || err != nil {
+			break
+		}
+		if err := togglePicks(selected, input); err != nil {
+			PrintError(err.Error())
+		}
+	}
+	var picked []int
+	for i, ok := range selected {
+		if ok {
+			picked = append(picked, i)
+		}
+	}
+	return picked
+}
+
+func togglePicks(selected []bool, input string) error {
+	for _, token := range strings.FieldsFunc(strings.ToLower(input), func(r rune) bool { return r == ' ' || r == ',' }) {
+		switch token {
+		case "a", "all":
+			setAll(selected, true)
+			continue
+		case "n", "none":
+			setAll(selected, false)
+			continue
+		}
+		from, to, isRange := strings.Cut(token, "-")
+		if !isRange {
+			to = from
+		}
+		start, errStart := strconv.Atoi(from)
+		end, errEnd := strconv.Atoi(to)
+		if errStart != nil || errEnd != nil || start < 1 || end > len(selected) || start > end {
+			return fmt.Errorf("invalid selection %q: use numbers between 1 and %d", token, len(selected))
+		}
+		for i := start - 1; i < end; i++ {
+			selected[i] = !selected[i]
+		}
+	}
+	return nil
+}
+
+func setAll(selected []bool, value bool) {
+	for i := range selected {
+		selected[i] = value
+	}
+}
+
+func PrintSplitPlan(plan string) {
+	fmt.Printf("%s Proposed commits:\n", infoPrefix)
+	for _, line := range strings.Split(strings.TrimRight(plan, "\n"), "\n") {
+		if strings.HasPrefix(line, "commit ") {
+			fmt.Printf("  %s\n", colorYellow.Sprint(line))
+		} else {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+}
+
+func AskSplitPlanAction(canApply bool) string {
+	options := "[e] edit"
+	if canApply {
+		options = "[a] apply  " + options
+	}
+	fmt.Printf("%s[Enter] cancel  %s: ", promptPrefix, options)
+	key := readSingleKey()
+	ClearLine()
+	return key
+}
+
+func EditText(editor, text string) (string, error) {
+	file, err := os.CreateTemp("", "yawn-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+	if _, err := file.WriteString(text); err != nil {
+		_ = file.Close()
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, file.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited file: %w", err)
+	}
+	return string(edited), nil
+}
+
+func PrintMessageComparison(current, proposed string) {
+	width := 100
+	if isTerminal {
+		if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+			width = w
+		}
+	}
+	column := max((width-3)/2, 20)
+	fmt.Printf("  %s │ %s\n", colorRed.Sprint(padRunes("Current", column)), colorGreen.Sprint("Proposed"))
+	for _, row := range sideBySide(current, proposed, column) {
+		fmt.Printf("  %s │ %s\n", colorRed.Sprint(row[0]), colorGreen.Sprint(row[1]))
+	}
+}
+
+func sideBySide(left, right string, column int) [][2]string {
+	leftLines, rightLines := wrapRunes(left, column), wrapRunes(right, column)
+	rows := make([][2]string, max(len(leftLines), len(rightLines)))
+	for i := range rows {
+		if i < len(leftLines) {
+			rows[i][0] = leftLines[i]
+		}
+		if i < len(rightLines) {
+			rows[i][1] = rightLines[i]
+		}
+		rows[i][0] = padRunes(rows[i][0], column)
+	}
+	return rows
+}
+
+func wrapRunes(text string, width int) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		runes := []rune(line)
+		for len(runes) > width {
+			lines = append(lines, string(runes[:width]))
+			runes = runes[width:]
+		}
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
+
+func padRunes(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-utf8.RuneCountInString(s), 0))
+}
+
+func AskRewordAction() string {
+	fmt.Printf("%s[Enter] skip  [a] accept  [e] edit  [q] stop reviewing: ", promptPrefix)
+	key := readSingleKey()
+	ClearLine()
+	return key
+}
+
+func PrintRecoverySteps(steps []string) {
+	fmt.Printf("%s To recover manually, run:\n", infoPrefix)
+	for _, step := range steps {
+		fmt.Printf("  %s\n", colorYellow.Sprint(step))
+	}
+}
@@ -2,6 +2,8 @@ package ui
 
 import (
 	"os"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -16,6 +18,7 @@ func TestPrintPreGenerationInfo(t *testing.T) {
 		additions  int
 		deletions  int
 		model      string
+		context    string
 	}{
 		{
 			name:       "known values",
@@ -38,11 +41,87 @@ func TestPrintPreGenerationInfo(t *testing.T) {
 			deletions:  100,
 			model:      "gemini-pro",
 		},
+		{
+			name:       "reduced diff context",
+			branchName: "main",
+			additions:  5000,
+			deletions:  3000,
+			model:      "gemini-flash-latest",
+			context:    "0 lines, whitespace ignored",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			PrintPreGenerationInfo(tc.branchName, tc.additions, tc.deletions, tc.model, tc.context)
+		})
+	}
+}
+
+func TestCountTerminalRows(t *testing.T) {
+	testCases := []struct {
+		name  string
+		text  string
+		width int
+		want  int
+	}{
+		{"single line", "fix: config", 80, 1},
+		{"trailing newline", "fix: config\n", 80, 2},
+		{"multiple lines", "fix: config\n\n- Body", 80, 3},
+		{"wrapped line", strings.Repeat("a", 25), 10, 3},
+		{"unknown width", strings.Repeat("a", 250), 0, 1},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			PrintPreGenerationInfo(tc.branchName, tc.additions, tc.deletions, tc.model)
+			if got := countTerminalRows(tc.text, tc.width); got != tc.want {
+				t.Errorf("countTerminalRows() = %d, want %d", got, tc.want)
+			}
 		})
 	}
 }
+
+func TestTogglePicks(t *testing.T) {
+	selected := make([]bool, 5)
+
+	if err := togglePicks(selected, "1 3-4"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []bool{true, false, true, true, false}; !slices.Equal(selected, want) {
+		t.Errorf("got %v, want %v", selected, want)
+	}
+
+	if err := togglePicks(selected, "3,5"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []bool{true, false, false, true, true}; !slices.Equal(selected, want) {
+		t.Errorf("got %v, want %v", selected, want)
+	}
+
+	if err := togglePicks(selected, "n a"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []bool{true, true, true, true, true}; !slices.Equal(selected, want) {
+		t.Errorf("got %v, want %v", selected, want)
+	}
+
+	for _, input := range []string{"0", "6", "4-2", "x"} {
+		if err := togglePicks(selected, input); err == nil {
+			t.Errorf("togglePicks(%q) should fail", input)
+		}
+	}
+}
+
+func TestSideBySide(t *testing.T) {
+	got := sideBySide("wip", "feat: add a very long subject\n\nbody", 10)
+	want := [][2]string{
+		{"wip       ", "feat: add "},
+		{"          ", "a very lon"},
+		{"          ", "g subject"},
+		{"          ", ""},
+		{"          ", "body"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("sideBySide() = %q, want %q", got, want)
+	}
+}